@@ -0,0 +1,603 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/yashbonde/vriksham/export"
+	Impl "github.com/yashbonde/vriksham/impl"
+	"github.com/yashbonde/vriksham/importer"
+	"github.com/yashbonde/vriksham/rpc"
+	"github.com/yashbonde/vriksham/server"
+	"google.golang.org/grpc"
+)
+
+// cli is what every command gets to work with
+type cli struct {
+	engine   Impl.TreeEngine
+	threadId string
+	json     bool
+	out      io.Writer
+}
+
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(c cli, args []string, ctx context.Context) error
+}
+
+// commands in the order they are listed by usage
+var commands = []command{
+	{"add", "[-role r] [-content c] [-author a] [-metadata json] [-thumbs n] [-score x] <id> [parent-id]", "add a message below the parent, or the root when it is left out", runAdd},
+	{"get", "[render flags]", "print the whole thread", runGet},
+	{"children", "[-depth n] [render flags] [id]", "print the messages below a message, or below the root", runChildren},
+	{"pick", "[start-id] [end-id]", "print the messages from the start (default root) to the end (default latest)", runPick},
+	{"latest", "", "print the latest message", runLatest},
+	{"set-latest", "<id>", "mark a message as the latest one", runSetLatest},
+	{"delete", "[id]", "delete a message and everything below it, or the whole thread", runDelete},
+	{"stats", "[id]", "print the size, breadth and depth of the thread and the degree of a message", runStats},
+	{"threads", "[-limit n] [-cursor c] [-order thread_id|activity] [-metadata json]", "list the stored threads a page at a time", runThreads},
+	{"info", "", "check the backend can be reached and print its version and capabilities", runInfo},
+	{"export", "[-format openai|anthropic|gemini|dot|mermaid] [-labels] [start-id] [end-id]", "print the messages from the start to the end as the request body of an LLM API, or draw the thread", runExport},
+	{"sft", "[-format openai|sharegpt] [-dedupe] [-preferred] [-all | thread-id ...]", "print every branch of the threads (default -t) as fine-tuning JSONL", runSFT},
+	{"dpo", "[-all | thread-id ...]", "print the preference pairs of rated answers in the threads (default -t) as JSONL", runDPO},
+	{"import", "chatgpt <conversations.json>", "store the conversations of a ChatGPT export, each under its own thread id", runImport},
+	{"load-demo", "", "store the demo tree under the thread id", runLoadDemo},
+	{"migrate", "", "bring the schema of the database upto date, for neo4j, postgres and sqlite", runMigrate},
+	{"serve", "[-addr host:port] [-grpc-addr host:port]", "serve the REST API, and the gRPC one when -grpc-addr is set", runServe},
+}
+
+func lookup(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+// errUsage makes main print how the command is used
+var errUsage = errors.New("wrong number of arguments")
+
+// flags parses the command flags, `min` and `max` bound the number of arguments left after them
+func flags(fs *flag.FlagSet, args []string, min, max int) ([]string, error) {
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() < min || fs.NArg() > max {
+		return nil, errUsage
+	}
+	return fs.Args(), nil
+}
+
+// optional turns the argument at `i` into a message, nil when it was not given
+func optional(args []string, i int) *Impl.Message {
+	if i >= len(args) || args[i] == "" {
+		return nil
+	}
+	return &Impl.Message{MessageId: args[i]}
+}
+
+func runAdd(c cli, args []string, ctx context.Context) error {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	role := fs.String("role", "", "role of the author")
+	content := fs.String("content", "", "content of the message")
+	author := fs.String("author", "", "author of the message")
+	metadata := fs.String("metadata", "", "metadata as a JSON object")
+	rating := Impl.Rating{}
+	fs.IntVar(&rating.Thumbs, "thumbs", 0, "rating, 1 for up and -1 for down")
+	fs.Float64Var(&rating.Score, "score", 0, "rating as a score")
+	args, err := flags(fs, args, 1, 2)
+	if err != nil {
+		return err
+	}
+	m := Impl.Message{MessageId: args[0], Role: *role, Content: *content, Author: *author}
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "thumbs" || f.Name == "score" {
+			m.Rating = &rating
+		}
+	})
+	if *metadata != "" {
+		if err := json.Unmarshal([]byte(*metadata), &m.Metadata); err != nil {
+			return fmt.Errorf("bad metadata: %w", err)
+		}
+	}
+	if err := c.engine.AddMessage(c.threadId, &m, optional(args, 1), ctx); err != nil {
+		return err
+	}
+	return c.message(m)
+}
+
+// renderFlags adds the RenderTree options to the flags of a command, listed in usage as [render flags]
+func renderFlags(fs *flag.FlagSet) *Impl.RenderOptions {
+	opts := &Impl.RenderOptions{}
+	fs.BoolVar(&opts.MarkLatest, "latest", true, "mark the latest message")
+	fs.BoolVar(&opts.ShowMetadata, "meta", false, "show the payload of every message")
+	fs.IntVar(&opts.CollapseChains, "collapse", 0, "fold chains of more than this many messages, 0 never folds")
+	fs.IntVar(&opts.MaxDepth, "max-depth", 0, "levels to draw, 0 draws all of them")
+	return opts
+}
+
+func runGet(c cli, args []string, ctx context.Context) error {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	opts := renderFlags(fs)
+	if _, err := flags(fs, args, 0, 0); err != nil {
+		return err
+	}
+	tree, err := c.engine.Get(c.threadId, ctx)
+	if err != nil {
+		return err
+	}
+	return c.tree(tree, *opts)
+}
+
+func runChildren(c cli, args []string, ctx context.Context) error {
+	fs := flag.NewFlagSet("children", flag.ContinueOnError)
+	depth := fs.Int("depth", 1, "levels to walk, at most 10")
+	opts := renderFlags(fs)
+	args, err := flags(fs, args, 0, 1)
+	if err != nil {
+		return err
+	}
+	tree, err := c.engine.GetChildren(c.threadId, optional(args, 0), *depth, ctx)
+	if err != nil {
+		return err
+	}
+	return c.tree(tree, *opts)
+}
+
+func runPick(c cli, args []string, ctx context.Context) error {
+	args, err := flags(flag.NewFlagSet("pick", flag.ContinueOnError), args, 0, 2)
+	if err != nil {
+		return err
+	}
+	a, b := pickArgs(args)
+	thread, err := c.engine.Pick(c.threadId, a, b, ctx)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.encode(thread)
+	}
+	for _, m := range thread.Messages {
+		fmt.Fprintln(c.out, describe(m))
+	}
+	return nil
+}
+
+// pickArgs reads the ids given to pick, a single id is where the thread ends
+func pickArgs(args []string) (*Impl.Message, *Impl.Message) {
+	if len(args) == 1 {
+		return nil, optional(args, 0)
+	}
+	return optional(args, 0), optional(args, 1)
+}
+
+func runExport(c cli, args []string, ctx context.Context) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "openai", "openai, anthropic, gemini, dot or mermaid")
+	opts := export.GraphOptions{}
+	fs.BoolVar(&opts.Labels, "labels", false, "add the role and content to the nodes of dot and mermaid")
+	args, err := flags(fs, args, 0, 2)
+	if err != nil {
+		return err
+	}
+
+	// the diagrams draw the whole tree
+	if *format == "dot" || *format == "mermaid" {
+		if len(args) > 0 {
+			return errUsage
+		}
+		tree, err := c.engine.Get(c.threadId, ctx)
+		if err != nil {
+			return err
+		}
+		draw := export.ToDOT
+		if *format == "mermaid" {
+			draw = export.ToMermaid
+		}
+		_, err = io.WriteString(c.out, draw(tree, opts))
+		return err
+	}
+
+	a, b := pickArgs(args)
+	thread, err := c.engine.Pick(c.threadId, a, b, ctx)
+	if err != nil {
+		return err
+	}
+	var body any
+	switch *format {
+	case "openai":
+		body, err = export.ToOpenAI(thread)
+	case "anthropic":
+		body, err = export.ToAnthropic(thread)
+	case "gemini":
+		body, err = export.ToGemini(thread)
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		return err
+	}
+	return c.encode(body)
+}
+
+func runLatest(c cli, args []string, ctx context.Context) error {
+	if _, err := flags(flag.NewFlagSet("latest", flag.ContinueOnError), args, 0, 0); err != nil {
+		return err
+	}
+	m, err := c.engine.GetLatestMessage(c.threadId, ctx)
+	if err != nil {
+		return err
+	}
+	return c.message(m)
+}
+
+func runSetLatest(c cli, args []string, ctx context.Context) error {
+	args, err := flags(flag.NewFlagSet("set-latest", flag.ContinueOnError), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	m, err := c.engine.SetLatestMessage(c.threadId, optional(args, 0), ctx)
+	if err != nil {
+		return err
+	}
+	return c.message(m)
+}
+
+func runDelete(c cli, args []string, ctx context.Context) error {
+	args, err := flags(flag.NewFlagSet("delete", flag.ContinueOnError), args, 0, 1)
+	if err != nil {
+		return err
+	}
+	message := optional(args, 0)
+	if err := c.engine.Delete(c.threadId, message, ctx); err != nil {
+		return err
+	}
+	deleted := c.threadId
+	if message != nil {
+		deleted = message.MessageId
+	}
+	return c.done("deleted", deleted)
+}
+
+// stats of a thread, Degree is for the message given to the command or the root
+type stats struct {
+	Size    int `json:"size"`
+	Breadth int `json:"breadth"`
+	Depth   int `json:"depth"`
+	Degree  int `json:"degree"`
+}
+
+func runStats(c cli, args []string, ctx context.Context) error {
+	args, err := flags(flag.NewFlagSet("stats", flag.ContinueOnError), args, 0, 1)
+	if err != nil {
+		return err
+	}
+	s := stats{}
+	if s.Size, err = c.engine.Size(c.threadId, ctx); err != nil {
+		return err
+	}
+	if s.Breadth, err = c.engine.Breadth(c.threadId, ctx); err != nil {
+		return err
+	}
+	if s.Depth, err = c.engine.Depth(c.threadId, ctx); err != nil {
+		return err
+	}
+	if s.Degree, err = c.engine.Degree(c.threadId, optional(args, 0), ctx); err != nil {
+		return err
+	}
+	if c.json {
+		return c.encode(s)
+	}
+	fmt.Fprintf(c.out, "size:    %d\nbreadth: %d\ndepth:   %d\ndegree:  %d\n", s.Size, s.Breadth, s.Depth, s.Degree)
+	return nil
+}
+
+func runInfo(c cli, args []string, ctx context.Context) error {
+	if _, err := flags(flag.NewFlagSet("info", flag.ContinueOnError), args, 0, 0); err != nil {
+		return err
+	}
+	if err := Impl.Ping(c.engine, ctx); err != nil {
+		return err
+	}
+	info, err := Impl.Info(c.engine, ctx)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.encode(info)
+	}
+	// a client prints the engine of its server below its own
+	for b := &info; b != nil; b = b.Remote {
+		capabilities := strings.Join(b.Capabilities, ", ")
+		if capabilities == "" {
+			capabilities = "none"
+		}
+		fmt.Fprintf(c.out, "backend:      %s\ncapabilities: %s\n", strings.TrimSpace(b.Name+" "+b.Version), capabilities)
+	}
+	return nil
+}
+
+// threadIds are the threads a command works on: the ones in `args`, every stored one with `all` or else the -t one
+func (c cli) threadIds(args []string, all bool, ctx context.Context) ([]string, error) {
+	if all && len(args) > 0 {
+		return nil, errUsage
+	} else if len(args) > 0 {
+		return args, nil
+	} else if !all {
+		return []string{c.threadId}, nil
+	}
+	threadIds := []string{}
+	opts := Impl.ListOptions{Limit: 1000}
+	for {
+		page, err := Impl.ListThreads(c.engine, opts, ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range page.Threads {
+			threadIds = append(threadIds, s.ThreadId)
+		}
+		if page.NextCursor == "" {
+			return threadIds, nil
+		}
+		opts.Cursor = page.NextCursor
+	}
+}
+
+func runThreads(c cli, args []string, ctx context.Context) error {
+	fs := flag.NewFlagSet("threads", flag.ContinueOnError)
+	opts := Impl.ListOptions{}
+	fs.IntVar(&opts.Limit, "limit", 20, "threads on the page")
+	fs.StringVar(&opts.Cursor, "cursor", "", "the next cursor printed with the previous page")
+	fs.StringVar(&opts.OrderBy, "order", Impl.ListByThreadId, "thread_id or activity for the most recently updated first")
+	metadata := fs.String("metadata", "", "only threads with a message that has this metadata, a JSON object")
+	if _, err := flags(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if *metadata != "" {
+		if err := json.Unmarshal([]byte(*metadata), &opts.Metadata); err != nil {
+			return fmt.Errorf("bad metadata: %w", err)
+		}
+	}
+	page, err := Impl.ListThreads(c.engine, opts, ctx)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.encode(page)
+	}
+	for _, s := range page.Threads {
+		updated, latest := "-", "-"
+		if !s.UpdatedAt.IsZero() {
+			updated = s.UpdatedAt.UTC().Format(time.RFC3339)
+		}
+		if s.Latest != nil {
+			latest = s.Latest.MessageId
+		}
+		fmt.Fprintf(c.out, "%s\tsize %d\tdepth %d\tupdated %s\tlatest %s\n", s.ThreadId, s.Size, s.Depth, updated, latest)
+	}
+	if page.NextCursor != "" {
+		fmt.Fprintf(c.out, "next: vriksham threads -cursor %s\n", page.NextCursor)
+	}
+	return nil
+}
+
+func runSFT(c cli, args []string, ctx context.Context) error {
+	fs := flag.NewFlagSet("sft", flag.ContinueOnError)
+	opts := export.SFTOptions{}
+	fs.StringVar(&opts.Format, "format", export.SFTOpenAI, "openai or sharegpt")
+	fs.BoolVar(&opts.Dedupe, "dedupe", false, "train on the messages branches share only once")
+	fs.BoolVar(&opts.Preferred, "preferred", false, "only the branches through the latest or a preferred message")
+	all := fs.Bool("all", false, "every stored thread")
+	args, err := flags(fs, args, 0, math.MaxInt)
+	if err != nil {
+		return err
+	}
+	threadIds, err := c.threadIds(args, *all, ctx)
+	if err != nil {
+		return err
+	}
+	for _, threadId := range threadIds {
+		tree, err := c.engine.Get(threadId, ctx)
+		if err != nil {
+			return err
+		}
+		if _, err := export.WriteSFT(c.out, tree, opts); err != nil {
+			return fmt.Errorf("thread %s: %w", threadId, err)
+		}
+	}
+	return nil
+}
+
+func runDPO(c cli, args []string, ctx context.Context) error {
+	fs := flag.NewFlagSet("dpo", flag.ContinueOnError)
+	all := fs.Bool("all", false, "every stored thread")
+	args, err := flags(fs, args, 0, math.MaxInt)
+	if err != nil {
+		return err
+	}
+	threadIds, err := c.threadIds(args, *all, ctx)
+	if err != nil {
+		return err
+	}
+	for _, threadId := range threadIds {
+		tree, err := c.engine.Get(threadId, ctx)
+		if err != nil {
+			return err
+		}
+		if _, err := export.WriteDPO(c.out, tree); err != nil {
+			return fmt.Errorf("thread %s: %w", threadId, err)
+		}
+	}
+	return nil
+}
+
+func runImport(c cli, args []string, ctx context.Context) error {
+	args, err := flags(flag.NewFlagSet("import", flag.ContinueOnError), args, 2, 2)
+	if err != nil {
+		return err
+	}
+	if args[0] != "chatgpt" {
+		return fmt.Errorf("unknown source %q, only chatgpt can be imported", args[0])
+	}
+	var in io.Reader = os.Stdin
+	if args[1] != "-" {
+		f, err := os.Open(args[1])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	report, err := importer.ImportChatGPT(in, c.engine, ctx)
+	if c.json {
+		if encodeErr := c.encode(report); err == nil {
+			err = encodeErr
+		}
+	} else {
+		fmt.Fprintf(c.out, "imported %d conversations, skipped %d already stored\n", len(report.Loaded), len(report.Skipped))
+	}
+	return err
+}
+
+// runMigrate reports what Migrate did, the backends also migrate on Connect so most runs only confirm the version
+func runMigrate(c cli, args []string, ctx context.Context) error {
+	if _, err := flags(flag.NewFlagSet("migrate", flag.ContinueOnError), args, 0, 0); err != nil {
+		return err
+	}
+	migrator, ok := c.engine.(Impl.Migrator)
+	if !ok {
+		return fmt.Errorf("the backend has no schema to migrate")
+	}
+	from, to, err := migrator.Migrate(ctx)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.encode(map[string]int{"from": from, "to": to})
+	}
+	if from == to {
+		_, err = fmt.Fprintf(c.out, "schema is at version %d\n", to)
+	} else {
+		_, err = fmt.Fprintf(c.out, "migrated the schema from version %d to %d\n", from, to)
+	}
+	return err
+}
+
+func runLoadDemo(c cli, args []string, ctx context.Context) error {
+	if _, err := flags(flag.NewFlagSet("load-demo", flag.ContinueOnError), args, 0, 0); err != nil {
+		return err
+	}
+	demo := Impl.GetDemoTree()
+	demo.Root.ThreadId = c.threadId
+	if err := c.engine.AddTree(c.threadId, *demo, ctx); err != nil {
+		return err
+	}
+	return c.done("loaded", c.threadId)
+}
+
+func runServe(c cli, args []string, ctx context.Context) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", envOr("VRIKSHAM_ADDR", ":8080"), "address of the REST API (env VRIKSHAM_ADDR)")
+	grpcAddr := fs.String("grpc-addr", os.Getenv("VRIKSHAM_GRPC_ADDR"), "address of the gRPC API, off when empty (env VRIKSHAM_GRPC_ADDR)")
+	if _, err := flags(fs, args, 0, 0); err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, 2)
+	srv := &http.Server{Addr: *addr, Handler: server.New(c.engine)}
+	go func() { errs <- srv.ListenAndServe() }()
+	log.Printf("serving %s on %s", target(), *addr)
+
+	grpcServer := grpc.NewServer()
+	if *grpcAddr != "" {
+		listener, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			srv.Close()
+			return err
+		}
+		rpc.Register(grpcServer, c.engine)
+		rpc.RegisterHealth(grpcServer, c.engine)
+		go func() { errs <- grpcServer.Serve(listener) }()
+		log.Printf("serving %s over gRPC on %s", target(), *grpcAddr)
+	}
+
+	var err error
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+	}
+	// let the requests in flight finish
+	shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	grpcServer.GracefulStop()
+	if shutdownErr := srv.Shutdown(shutdown); err == nil {
+		err = shutdownErr
+	}
+	return err
+}
+
+// output
+
+func (c cli) encode(v any) error {
+	enc := json.NewEncoder(c.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (c cli) message(m Impl.Message) error {
+	if c.json {
+		return c.encode(m)
+	}
+	_, err := fmt.Fprintln(c.out, describe(m))
+	return err
+}
+
+func (c cli) done(action, id string) error {
+	if c.json {
+		return c.encode(map[string]string{"thread_id": c.threadId, action: id})
+	}
+	_, err := fmt.Fprintf(c.out, "%s %s\n", action, id)
+	return err
+}
+
+func (c cli) tree(tree Impl.ThreadTree, opts Impl.RenderOptions) error {
+	if c.json {
+		return c.encode(tree)
+	}
+	_, err := io.WriteString(c.out, Impl.RenderTree(tree, opts))
+	return err
+}
+
+// describe is the one line form of a message
+func describe(m Impl.Message) string {
+	s := m.MessageId
+	if m.Latest {
+		s += " (latest)"
+	}
+	if m.Role != "" {
+		s += " [" + m.Role + "]"
+	}
+	if m.Content != "" {
+		s += " " + strings.ReplaceAll(m.Content, "\n", " ")
+	}
+	return s
+}
@@ -0,0 +1,222 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	Impl "github.com/yashbonde/vriksham/impl"
+)
+
+// OpenAI Chat Completions
+
+type OpenAIMessage struct {
+	Role       string           `json:"role"`
+	Content    string           `json:"content,omitempty"`
+	ToolCalls  []OpenAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallId string           `json:"tool_call_id,omitempty"`
+}
+
+type OpenAIToolCall struct {
+	Id       string         `json:"id"`
+	Type     string         `json:"type"`
+	Function OpenAIFunction `json:"function"`
+}
+
+// OpenAIFunction carries the arguments as a JSON string the way the API does
+type OpenAIFunction struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// ToOpenAI returns the `messages` of a Chat Completions request, system and developer messages stay in place
+func ToOpenAI(thread Impl.Thread) ([]OpenAIMessage, error) {
+	out := make([]OpenAIMessage, 0, len(thread.Messages))
+	for _, m := range thread.Messages {
+		r, err := role(m)
+		if err != nil {
+			return nil, err
+		}
+		om := OpenAIMessage{Role: r, Content: m.Content}
+		switch r {
+		case roleAssistant:
+			calls, err := ToolCalls(m)
+			if err != nil {
+				return nil, err
+			}
+			for _, call := range calls {
+				args, err := json.Marshal(call.Arguments)
+				if err != nil {
+					return nil, fmt.Errorf("arguments of tool call %s on message %s: %w", call.Id, m.MessageId, err)
+				}
+				om.ToolCalls = append(om.ToolCalls, OpenAIToolCall{
+					Id:       call.Id,
+					Type:     "function",
+					Function: OpenAIFunction{Name: call.Name, Arguments: string(args)},
+				})
+			}
+		case roleTool:
+			om.ToolCallId, _ = toolResult(m)
+		}
+		out = append(out, om)
+	}
+	return out, nil
+}
+
+// Anthropic Messages API
+
+type AnthropicRequest struct {
+	System   string             `json:"system,omitempty"`
+	Messages []AnthropicMessage `json:"messages"`
+}
+
+type AnthropicMessage struct {
+	Role    string           `json:"role"`
+	Content []AnthropicBlock `json:"content"`
+}
+
+// AnthropicBlock is a text, tool_use or tool_result content block
+type AnthropicBlock struct {
+	Type      string `json:"type"`
+	Text      string `json:"text,omitempty"`
+	Id        string `json:"id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Input     any    `json:"input,omitempty"`
+	ToolUseId string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+}
+
+/*
+ToAnthropic returns the `system` and `messages` of a Messages API request. The API only takes a system prompt at the
+top so system and developer messages are joined into it. Tool results are sent by the user and messages in a row
+from the same side are merged into one, since the API wants the roles to alternate.
+*/
+func ToAnthropic(thread Impl.Thread) (AnthropicRequest, error) {
+	req := AnthropicRequest{Messages: []AnthropicMessage{}}
+	system := []string{}
+	for _, m := range thread.Messages {
+		r, err := role(m)
+		if err != nil {
+			return AnthropicRequest{}, err
+		}
+		blocks := []AnthropicBlock{}
+		switch r {
+		case roleSystem, roleDeveloper:
+			system = append(system, m.Content)
+			continue
+		case roleUser:
+			blocks = append(blocks, AnthropicBlock{Type: "text", Text: m.Content})
+		case roleAssistant:
+			if m.Content != "" {
+				blocks = append(blocks, AnthropicBlock{Type: "text", Text: m.Content})
+			}
+			calls, err := ToolCalls(m)
+			if err != nil {
+				return AnthropicRequest{}, err
+			}
+			for _, call := range calls {
+				blocks = append(blocks, AnthropicBlock{Type: "tool_use", Id: call.Id, Name: call.Name, Input: call.Arguments})
+			}
+		case roleTool:
+			r = roleUser
+			id, _ := toolResult(m)
+			blocks = append(blocks, AnthropicBlock{Type: "tool_result", ToolUseId: id, Content: m.Content})
+		}
+
+		if n := len(req.Messages); n > 0 && req.Messages[n-1].Role == r {
+			req.Messages[n-1].Content = append(req.Messages[n-1].Content, blocks...)
+		} else {
+			req.Messages = append(req.Messages, AnthropicMessage{Role: r, Content: blocks})
+		}
+	}
+	req.System = strings.Join(system, "\n\n")
+	return req, nil
+}
+
+// Gemini generateContent
+
+type GeminiRequest struct {
+	SystemInstruction *GeminiContent  `json:"systemInstruction,omitempty"`
+	Contents          []GeminiContent `json:"contents"`
+}
+
+type GeminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []GeminiPart `json:"parts"`
+}
+
+type GeminiPart struct {
+	Text             string                  `json:"text,omitempty"`
+	FunctionCall     *GeminiFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *GeminiFunctionResponse `json:"functionResponse,omitempty"`
+}
+
+type GeminiFunctionCall struct {
+	Name string         `json:"name"`
+	Args map[string]any `json:"args"`
+}
+
+type GeminiFunctionResponse struct {
+	Name     string         `json:"name"`
+	Response map[string]any `json:"response"`
+}
+
+/*
+ToGemini returns the `systemInstruction` and `contents` of a generateContent request. Assistant messages are sent by
+the model and tool results by the user, with messages in a row from the same side merged like ToAnthropic does.
+
+Gemini matches a function response by name, a tool message without a "name" in its metadata takes the name of the
+call it answers. A response has to be an object, content that is not a JSON object is sent as {"content": ...}.
+*/
+func ToGemini(thread Impl.Thread) (GeminiRequest, error) {
+	req := GeminiRequest{Contents: []GeminiContent{}}
+	toolNames := map[string]string{}
+	for _, m := range thread.Messages {
+		r, err := role(m)
+		if err != nil {
+			return GeminiRequest{}, err
+		}
+		parts := []GeminiPart{}
+		switch r {
+		case roleSystem, roleDeveloper:
+			if req.SystemInstruction == nil {
+				req.SystemInstruction = &GeminiContent{}
+			}
+			req.SystemInstruction.Parts = append(req.SystemInstruction.Parts, GeminiPart{Text: m.Content})
+			continue
+		case roleUser:
+			parts = append(parts, GeminiPart{Text: m.Content})
+		case roleAssistant:
+			r = roleModel
+			if m.Content != "" {
+				parts = append(parts, GeminiPart{Text: m.Content})
+			}
+			calls, err := ToolCalls(m)
+			if err != nil {
+				return GeminiRequest{}, err
+			}
+			for _, call := range calls {
+				toolNames[call.Id] = call.Name
+				parts = append(parts, GeminiPart{FunctionCall: &GeminiFunctionCall{Name: call.Name, Args: call.Arguments}})
+			}
+		case roleTool:
+			r = roleUser
+			id, name := toolResult(m)
+			if name == "" {
+				name = toolNames[id]
+			}
+			response := map[string]any{}
+			if json.Unmarshal([]byte(m.Content), &response) != nil || response == nil {
+				response = map[string]any{"content": m.Content}
+			}
+			parts = append(parts, GeminiPart{FunctionResponse: &GeminiFunctionResponse{Name: name, Response: response}})
+		}
+
+		if n := len(req.Contents); n > 0 && req.Contents[n-1].Role == r {
+			req.Contents[n-1].Parts = append(req.Contents[n-1].Parts, parts...)
+		} else {
+			req.Contents = append(req.Contents, GeminiContent{Role: r, Parts: parts})
+		}
+	}
+	return req, nil
+}
@@ -0,0 +1,129 @@
+package export_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/yashbonde/vriksham/export"
+	Impl "github.com/yashbonde/vriksham/impl"
+)
+
+// toolThread asks for the weather, calls a tool once and answers. The arguments of the call are stored the way OpenAI
+// returns them, as a JSON string.
+var toolThread = Impl.Thread{Messages: []Impl.Message{
+	{MessageId: "m0", Role: "system", Content: "Be brief."},
+	{MessageId: "m1", Role: "user", Content: "Weather in Pune?"},
+	{MessageId: "m2", Role: "assistant", Metadata: map[string]any{
+		"tool_calls": []any{map[string]any{"id": "call_0", "name": "weather", "arguments": `{"city":"Pune"}`}},
+	}},
+	{MessageId: "m3", Role: "tool", Content: `{"celsius":31}`, Metadata: map[string]any{"tool_call_id": "call_0"}},
+	{MessageId: "m4", Role: "assistant", Content: "31°C and sunny."},
+}}
+
+// expectJSON compares the encoding of `v` with `want` ignoring the whitespace in `want`
+func expectJSON(t *testing.T, name string, v any, want string) {
+	t.Helper()
+	got, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	compact := &bytes.Buffer{}
+	if err := json.Compact(compact, []byte(want)); err != nil {
+		t.Fatalf("%s: bad expectation: %v", name, err)
+	}
+	if string(got) != compact.String() {
+		t.Errorf("%s:\ngot  %s\nwant %s", name, got, compact)
+	}
+}
+
+func TestToOpenAI(t *testing.T) {
+	messages, err := export.ToOpenAI(toolThread)
+	if err != nil {
+		t.Fatalf("ToOpenAI: %v", err)
+	}
+	expectJSON(t, "ToOpenAI", messages, `[
+		{"role": "system", "content": "Be brief."},
+		{"role": "user", "content": "Weather in Pune?"},
+		{"role": "assistant", "tool_calls": [{"id": "call_0", "type": "function", "function": {"name": "weather", "arguments": "{\"city\":\"Pune\"}"}}]},
+		{"role": "tool", "content": "{\"celsius\":31}", "tool_call_id": "call_0"},
+		{"role": "assistant", "content": "31°C and sunny."}
+	]`)
+}
+
+func TestToAnthropic(t *testing.T) {
+	req, err := export.ToAnthropic(toolThread)
+	if err != nil {
+		t.Fatalf("ToAnthropic: %v", err)
+	}
+	expectJSON(t, "ToAnthropic", req, `{
+		"system": "Be brief.",
+		"messages": [
+			{"role": "user", "content": [{"type": "text", "text": "Weather in Pune?"}]},
+			{"role": "assistant", "content": [{"type": "tool_use", "id": "call_0", "name": "weather", "input": {"city": "Pune"}}]},
+			{"role": "user", "content": [{"type": "tool_result", "tool_use_id": "call_0", "content": "{\"celsius\":31}"}]},
+			{"role": "assistant", "content": [{"type": "text", "text": "31°C and sunny."}]}
+		]
+	}`)
+
+	// a user message after a tool result goes in the same turn
+	thread := Impl.Thread{Messages: append(toolThread.Messages[:4:4], Impl.Message{MessageId: "m5", Role: "user", Content: "and tomorrow?"})}
+	req, err = export.ToAnthropic(thread)
+	if err != nil {
+		t.Fatalf("ToAnthropic: %v", err)
+	}
+	if len(req.Messages) != 3 || len(req.Messages[2].Content) != 2 {
+		t.Errorf("ToAnthropic: got %+v, want the tool result and the question in one user turn", req.Messages)
+	}
+}
+
+func TestToGemini(t *testing.T) {
+	req, err := export.ToGemini(toolThread)
+	if err != nil {
+		t.Fatalf("ToGemini: %v", err)
+	}
+	expectJSON(t, "ToGemini", req, `{
+		"systemInstruction": {"parts": [{"text": "Be brief."}]},
+		"contents": [
+			{"role": "user", "parts": [{"text": "Weather in Pune?"}]},
+			{"role": "model", "parts": [{"functionCall": {"name": "weather", "args": {"city": "Pune"}}}]},
+			{"role": "user", "parts": [{"functionResponse": {"name": "weather", "response": {"celsius": 31}}}]},
+			{"role": "model", "parts": [{"text": "31°C and sunny."}]}
+		]
+	}`)
+}
+
+func TestUnknownRole(t *testing.T) {
+	thread := Impl.Thread{Messages: []Impl.Message{{MessageId: "m0", Role: "narrator"}}}
+	if _, err := export.ToOpenAI(thread); !errors.Is(err, export.ErrUnknownRole) {
+		t.Errorf("ToOpenAI: got %v, want ErrUnknownRole", err)
+	}
+	if _, err := export.ToAnthropic(thread); !errors.Is(err, export.ErrUnknownRole) {
+		t.Errorf("ToAnthropic: got %v, want ErrUnknownRole", err)
+	}
+	if _, err := export.ToGemini(thread); !errors.Is(err, export.ErrUnknownRole) {
+		t.Errorf("ToGemini: got %v, want ErrUnknownRole", err)
+	}
+}
+
+func TestPath(t *testing.T) {
+	demo := *Impl.GetDemoTree()
+	thread, err := export.Path(demo, "msg_21")
+	if err != nil {
+		t.Fatalf("Path: %v", err)
+	}
+	ids := []string{}
+	for _, m := range thread.Messages {
+		ids = append(ids, m.MessageId)
+	}
+	expectJSON(t, "Path", ids, `["msg_00", "msg_06", "msg_16", "msg_17", "msg_20", "msg_21"]`)
+
+	thread, err = export.Path(demo, "")
+	if err != nil || len(thread.Messages) != 8 || thread.Messages[7].MessageId != "msg_27" {
+		t.Errorf("Path to latest: got %v, %v", thread, err)
+	}
+	if _, err := export.Path(demo, "unknown"); !errors.Is(err, Impl.ErrMessageNotFound) {
+		t.Errorf("Path(unknown): got %v, want ErrMessageNotFound", err)
+	}
+}
@@ -0,0 +1,108 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+
+	Impl "github.com/yashbonde/vriksham/impl"
+)
+
+/*
+PreferencePair is a record for preference tuning (DPO) in the conversational format of most trainers. The prompt is
+the path from the root to the parent the two answers share, both answers are a single assistant message.
+*/
+type PreferencePair struct {
+	Prompt   []OpenAIMessage `json:"prompt"`
+	Chosen   []OpenAIMessage `json:"chosen"`
+	Rejected []OpenAIMessage `json:"rejected"`
+}
+
+// compareRatings orders ratings by thumbs and then by score
+func compareRatings(a, b Impl.Rating) int {
+	if a.Thumbs != b.Thumbs {
+		return a.Thumbs - b.Thumbs
+	} else if a.Score < b.Score {
+		return -1
+	} else if a.Score > b.Score {
+		return 1
+	}
+	return 0
+}
+
+/*
+PreferencePairs returns a pair for every two rated assistant messages under the same parent whose ratings differ, the
+better rated one is chosen. Unrated messages and the messages right below the root, which have no prompt, are left out.
+*/
+func PreferencePairs(tree Impl.ThreadTree) ([]PreferencePair, error) {
+	messages := map[string]Impl.Message{}
+	for _, m := range tree.Messages {
+		messages[m.MessageId] = m
+	}
+	// rated siblings in the order of the relations so the pairs come out the same every time
+	parents := []string{}
+	rated := map[string][]Impl.Message{}
+	for _, r := range tree.Relations {
+		m, ok := messages[r.EndId]
+		if !ok || r.StartId == "" || m.Rating == nil {
+			continue
+		}
+		if role, err := role(m); err != nil || role != roleAssistant {
+			continue
+		}
+		if _, ok := rated[r.StartId]; !ok {
+			parents = append(parents, r.StartId)
+		}
+		rated[r.StartId] = append(rated[r.StartId], m)
+	}
+
+	pairs := []PreferencePair{}
+	for _, parentId := range parents {
+		siblings := rated[parentId]
+		if len(siblings) < 2 {
+			continue
+		}
+		path, err := Path(tree, parentId)
+		if err != nil {
+			return nil, err
+		}
+		prompt, err := ToOpenAI(path)
+		if err != nil {
+			return nil, err
+		}
+		for i, a := range siblings {
+			for _, b := range siblings[i+1:] {
+				chosen, rejected := a, b
+				switch c := compareRatings(*a.Rating, *b.Rating); {
+				case c == 0:
+					continue
+				case c < 0:
+					chosen, rejected = b, a
+				}
+				pair := PreferencePair{Prompt: prompt}
+				if pair.Chosen, err = ToOpenAI(Impl.Thread{Messages: []Impl.Message{chosen}}); err != nil {
+					return nil, err
+				}
+				if pair.Rejected, err = ToOpenAI(Impl.Thread{Messages: []Impl.Message{rejected}}); err != nil {
+					return nil, err
+				}
+				pairs = append(pairs, pair)
+			}
+		}
+	}
+	return pairs, nil
+}
+
+// WriteDPO writes the PreferencePairs of `tree` to `w` as JSON lines and returns how many it wrote
+func WriteDPO(w io.Writer, tree Impl.ThreadTree) (int, error) {
+	pairs, err := PreferencePairs(tree)
+	if err != nil {
+		return 0, err
+	}
+	enc := json.NewEncoder(w)
+	for i, pair := range pairs {
+		if err := enc.Encode(pair); err != nil {
+			return i, err
+		}
+	}
+	return len(pairs), nil
+}
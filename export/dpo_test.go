@@ -0,0 +1,48 @@
+package export_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/yashbonde/vriksham/export"
+	Impl "github.com/yashbonde/vriksham/impl"
+)
+
+func TestPreferencePairs(t *testing.T) {
+	tree := chatTree()
+	ratings := map[string]*Impl.Rating{
+		"a1":  {Thumbs: 1},
+		"a1b": {Thumbs: -1},
+		"a2":  {Score: 0.4},
+		"a2b": {Score: 0.9},
+		"u3":  {Thumbs: -1}, // not an answer
+	}
+	for i, m := range tree.Messages {
+		tree.Messages[i].Rating = ratings[m.MessageId]
+	}
+
+	pairs, err := export.PreferencePairs(tree)
+	if err != nil {
+		t.Fatalf("PreferencePairs: %v", err)
+	}
+	if len(pairs) != 2 {
+		t.Fatalf("PreferencePairs: got %d pairs, want 2", len(pairs))
+	}
+	expectJSON(t, "PreferencePairs", pairs[0], `{
+		"prompt": [{"role": "system", "content": "Be brief."}, {"role": "user", "content": "Hi"}],
+		"chosen": [{"role": "assistant", "content": "Hello!"}],
+		"rejected": [{"role": "assistant", "content": "Hey."}]
+	}`)
+	if len(pairs[1].Prompt) != 4 || pairs[1].Chosen[0].Content != "See you." || pairs[1].Rejected[0].Content != "Bye!" {
+		t.Errorf("PreferencePairs: got %+v for the second answer", pairs[1])
+	}
+
+	// equal ratings say nothing about which answer is better
+	tree.Messages[6].Rating = &Impl.Rating{Thumbs: 1}
+	b := &bytes.Buffer{}
+	n, err := export.WriteDPO(b, tree)
+	if err != nil || n != 1 || strings.Count(b.String(), "\n") != 1 {
+		t.Errorf("WriteDPO: got %d pairs, %v in %q", n, err, b)
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Package export turns threads into the formats other tools read, like the request bodies of the LLM providers:
+
+	thread, err := backend.Pick(threadId, nil, nil, ctx)
+	messages, err := export.ToOpenAI(thread)
+
+The role of a message is one of system, developer, user, assistant (or model) and tool. Tool calls are read from the
+metadata of a message so they survive every backend:
+
+	assistant: {"tool_calls": [{"id": "call_0", "name": "search", "arguments": {"q": "banyan"}}]}
+	tool:      {"tool_call_id": "call_0", "name": "search"}
+
+The arguments can also be the JSON string OpenAI returns. The content of a tool message is the result of the call.
+*/
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	Impl "github.com/yashbonde/vriksham/impl"
+)
+
+// ErrUnknownRole is returned for a message whose role has no counterpart in the format
+var ErrUnknownRole = errors.New("unknown role")
+
+// the roles every exporter understands
+const (
+	roleSystem    = "system"
+	roleDeveloper = "developer"
+	roleUser      = "user"
+	roleAssistant = "assistant"
+	roleModel     = "model"
+	roleTool      = "tool"
+)
+
+// role returns the role of the message with the aliases folded in
+func role(m Impl.Message) (string, error) {
+	switch m.Role {
+	case roleSystem, roleDeveloper, roleUser, roleAssistant, roleTool:
+		return m.Role, nil
+	case roleModel:
+		return roleAssistant, nil
+	}
+	return "", fmt.Errorf("%w %q on message %s", ErrUnknownRole, m.Role, m.MessageId)
+}
+
+// ToolCall is a call to a tool made by an assistant message
+type ToolCall struct {
+	Id        string         `json:"id"`
+	Name      string         `json:"name"`
+	Arguments map[string]any `json:"arguments"`
+}
+
+// ToolCalls reads the calls stored under "tool_calls" in the metadata of `m`
+func ToolCalls(m Impl.Message) ([]ToolCall, error) {
+	raw, ok := m.Metadata["tool_calls"]
+	if !ok {
+		return nil, nil
+	}
+	// go through JSON so both the decoded metadata of a backend and values set in code are read the same way
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("tool_calls of message %s: %w", m.MessageId, err)
+	}
+	stored := []struct {
+		Id        string          `json:"id"`
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}{}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("tool_calls of message %s: %w", m.MessageId, err)
+	}
+	calls := make([]ToolCall, 0, len(stored))
+	for _, s := range stored {
+		call := ToolCall{Id: s.Id, Name: s.Name, Arguments: map[string]any{}}
+		args := s.Arguments
+		var text string
+		if json.Unmarshal(args, &text) == nil {
+			args = json.RawMessage(text)
+		}
+		if len(args) > 0 && string(args) != "null" {
+			if err := json.Unmarshal(args, &call.Arguments); err != nil {
+				return nil, fmt.Errorf("arguments of tool call %s on message %s: %w", s.Id, m.MessageId, err)
+			}
+		}
+		calls = append(calls, call)
+	}
+	return calls, nil
+}
+
+// toolResult returns the id of the call a tool message answers and the name of the tool
+func toolResult(m Impl.Message) (string, string) {
+	id, _ := m.Metadata["tool_call_id"].(string)
+	name, _ := m.Metadata["name"].(string)
+	return id, name
+}
+
+/*
+Path returns the messages from the root of `tree` down to `messageId`, the same thread Pick returns for it. An empty
+`messageId` ends the path at the latest message.
+*/
+func Path(tree Impl.ThreadTree, messageId string) (Impl.Thread, error) {
+	messages := map[string]Impl.Message{}
+	for _, m := range tree.Messages {
+		messages[m.MessageId] = m
+		if messageId == "" && m.Latest {
+			messageId = m.MessageId
+		}
+	}
+	if messageId == "" {
+		return Impl.Thread{}, fmt.Errorf("%w in thread %s", Impl.ErrNoLatest, tree.Root.ThreadId)
+	}
+	parents := map[string]string{}
+	for _, r := range tree.Relations {
+		parents[r.EndId] = r.StartId
+	}
+
+	path := []Impl.Message{}
+	for id := messageId; id != ""; id = parents[id] {
+		m, ok := messages[id]
+		if !ok {
+			return Impl.Thread{}, &Impl.MessageError{ThreadId: tree.Root.ThreadId, MessageId: id, Err: Impl.ErrMessageNotFound}
+		} else if len(path) == len(messages) {
+			return Impl.Thread{}, fmt.Errorf("%w: cycle through %s", Impl.ErrInvalidTree, id)
+		}
+		path = append(path, m)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return Impl.Thread{Messages: path}, nil
+}
@@ -0,0 +1,184 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	Impl "github.com/yashbonde/vriksham/impl"
+)
+
+// GraphOptions change what ToDOT and ToMermaid draw
+type GraphOptions struct {
+	// Labels adds the role and the start of the content below the id of every message
+	Labels bool
+
+	// MaxLabel is the number of characters of the content shown with Labels, 40 when 0
+	MaxLabel int
+}
+
+// colors of the highlighted path and the latest message
+const (
+	pathColor   = "#1f6feb"
+	latestColor = "#ffd33d"
+)
+
+// graph is a ThreadTree laid out for drawing, the root has the empty id
+type graph struct {
+	threadId string
+	ids      []string // messages in the order of the tree
+	messages map[string]Impl.Message
+	edges    []Impl.Triple
+	onPath   map[string]bool // messages from the root to the latest one
+	latest   string
+}
+
+func newGraph(tree Impl.ThreadTree) graph {
+	g := graph{threadId: tree.Root.ThreadId, messages: map[string]Impl.Message{}, onPath: map[string]bool{}}
+	for _, m := range tree.Messages {
+		g.ids = append(g.ids, m.MessageId)
+		g.messages[m.MessageId] = m
+		if m.Latest {
+			g.latest = m.MessageId
+		}
+	}
+	for _, r := range tree.Relations {
+		if _, ok := g.messages[r.EndId]; !ok {
+			continue
+		} else if _, ok := g.messages[r.StartId]; !ok {
+			// a tree returned by GetChildren starts below a message that is not in it
+			r.StartId = ""
+		}
+		g.edges = append(g.edges, r)
+	}
+	if path, err := Path(tree, ""); err == nil {
+		for _, m := range path.Messages {
+			g.onPath[m.MessageId] = true
+		}
+	}
+	return g
+}
+
+// edgeOnPath tells if the edge is a step of the path to the latest message
+func (g graph) edgeOnPath(e Impl.Triple) bool {
+	return g.onPath[e.EndId] && (e.StartId == "" || g.onPath[e.StartId])
+}
+
+// label returns the lines shown for a message
+func (g graph) label(id string, opts GraphOptions) []string {
+	m := g.messages[id]
+	lines := []string{id}
+	if !opts.Labels {
+		return lines
+	}
+	limit := opts.MaxLabel
+	if limit <= 0 {
+		limit = 40
+	}
+	content := []rune(strings.Join(strings.Fields(m.Content), " "))
+	text := string(content)
+	if len(content) > limit {
+		text = string(content[:limit]) + "…"
+	}
+	if m.Role != "" && text != "" {
+		lines = append(lines, m.Role+": "+text)
+	} else if m.Role != "" || text != "" {
+		lines = append(lines, m.Role+text)
+	}
+	return lines
+}
+
+/*
+ToDOT draws the tree for Graphviz, `dot -Tsvg`. The path from the root to the latest message is drawn in blue and
+the latest message is filled in.
+*/
+func ToDOT(tree Impl.ThreadTree, opts GraphOptions) string {
+	g := newGraph(tree)
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "digraph %s {\n", dotQuote(g.threadId))
+	b.WriteString("  node [shape=box, style=rounded, fontname=\"Helvetica\"];\n")
+	fmt.Fprintf(b, "  root [label=%s, shape=ellipse];\n", dotQuote(g.threadId))
+	for _, id := range g.ids {
+		attrs := []string{"label=" + dotQuote(strings.Join(g.label(id, opts), "\n"))}
+		if id == g.latest {
+			attrs = append(attrs, `style="rounded,filled"`, fmt.Sprintf("fillcolor=%q", latestColor))
+		}
+		if g.onPath[id] {
+			attrs = append(attrs, fmt.Sprintf("color=%q", pathColor), "penwidth=2")
+		}
+		fmt.Fprintf(b, "  %s [%s];\n", dotQuote(id), strings.Join(attrs, ", "))
+	}
+	for _, e := range g.edges {
+		start := "root"
+		if e.StartId != "" {
+			start = dotQuote(e.StartId)
+		}
+		attrs := ""
+		if g.edgeOnPath(e) {
+			attrs = fmt.Sprintf(" [color=%q, penwidth=2]", pathColor)
+		}
+		fmt.Fprintf(b, "  %s -> %s%s;\n", start, dotQuote(e.EndId), attrs)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotQuote returns `s` as a DOT string, a newline starts a new line of the label
+func dotQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+	return `"` + s + `"`
+}
+
+/*
+ToMermaid draws the tree as a Mermaid flowchart (`graph TD`) that renders in GitHub markdown. Messages get the ids
+n0, n1, ... in the order of the tree since Mermaid ids cannot hold every character a message id can, the path to the
+latest message and the latest message are highlighted like ToDOT does.
+*/
+func ToMermaid(tree Impl.ThreadTree, opts GraphOptions) string {
+	g := newGraph(tree)
+	nodes := map[string]string{"": "root"}
+	for i, id := range g.ids {
+		nodes[id] = fmt.Sprintf("n%d", i)
+	}
+
+	b := &strings.Builder{}
+	b.WriteString("graph TD\n")
+	fmt.Fprintf(b, "  root([%s])\n", mermaidQuote([]string{g.threadId}))
+	for _, id := range g.ids {
+		fmt.Fprintf(b, "  %s[%s]\n", nodes[id], mermaidQuote(g.label(id, opts)))
+	}
+	onPath := []string{}
+	for i, e := range g.edges {
+		fmt.Fprintf(b, "  %s --> %s\n", nodes[e.StartId], nodes[e.EndId])
+		if g.edgeOnPath(e) {
+			onPath = append(onPath, fmt.Sprint(i))
+		}
+	}
+
+	pathNodes := []string{}
+	for _, id := range g.ids {
+		if g.onPath[id] && id != g.latest {
+			pathNodes = append(pathNodes, nodes[id])
+		}
+	}
+	if len(pathNodes) > 0 {
+		fmt.Fprintf(b, "  classDef path stroke:%s,stroke-width:2px\n", pathColor)
+		fmt.Fprintf(b, "  class %s path\n", strings.Join(pathNodes, ","))
+	}
+	if g.latest != "" {
+		fmt.Fprintf(b, "  classDef latest fill:%s,stroke:%s,stroke-width:2px\n", latestColor, pathColor)
+		fmt.Fprintf(b, "  class %s latest\n", nodes[g.latest])
+	}
+	if len(onPath) > 0 {
+		fmt.Fprintf(b, "  linkStyle %s stroke:%s,stroke-width:2px\n", strings.Join(onPath, ","), pathColor)
+	}
+	return b.String()
+}
+
+// mermaidQuote returns the lines as a quoted Mermaid label, quotes and markup are written as entities
+func mermaidQuote(lines []string) string {
+	r := strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;")
+	for i, l := range lines {
+		lines[i] = r.Replace(l)
+	}
+	return `"` + strings.Join(lines, "<br/>") + `"`
+}
@@ -0,0 +1,75 @@
+package export_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yashbonde/vriksham/export"
+	Impl "github.com/yashbonde/vriksham/impl"
+)
+
+func TestToMermaid(t *testing.T) {
+	got := export.ToMermaid(chatTree(), export.GraphOptions{})
+	want := `graph TD
+  root(["chat"])
+  n0["s"]
+  n1["u1"]
+  n2["a1"]
+  n3["u2"]
+  n4["a2"]
+  n5["a2b"]
+  n6["a1b"]
+  n7["u3"]
+  root --> n0
+  n0 --> n1
+  n1 --> n2
+  n2 --> n3
+  n3 --> n4
+  n3 --> n5
+  n1 --> n6
+  n0 --> n7
+  classDef path stroke:#1f6feb,stroke-width:2px
+  class n0,n1,n2,n3 path
+  classDef latest fill:#ffd33d,stroke:#1f6feb,stroke-width:2px
+  class n4 latest
+  linkStyle 0,1,2,3,4 stroke:#1f6feb,stroke-width:2px
+`
+	if got != want {
+		t.Errorf("ToMermaid:\n%s\nwant\n%s", got, want)
+	}
+
+	got = export.ToMermaid(chatTree(), export.GraphOptions{Labels: true, MaxLabel: 4})
+	if want := `n0["s<br/>system: Be b…"]`; !strings.Contains(got, want) {
+		t.Errorf("ToMermaid with Labels: got\n%s\nwant it to contain %s", got, want)
+	}
+}
+
+func TestToDOT(t *testing.T) {
+	tree := chatTree()
+	tree.Messages[1].Content = `Say "hi"`
+	got := export.ToDOT(tree, export.GraphOptions{Labels: true})
+	for _, want := range []string{
+		`digraph "chat" {`,
+		`root [label="chat", shape=ellipse];`,
+		`"u1" [label="u1\nuser: Say \"hi\"", color="#1f6feb", penwidth=2];`,
+		`"a2" [label="a2\nassistant: Bye!", style="rounded,filled", fillcolor="#ffd33d", color="#1f6feb", penwidth=2];`,
+		`"a2b" [label="a2b\nassistant: See you."];`,
+		`root -> "s" [color="#1f6feb", penwidth=2];`,
+		`"u2" -> "a2b";`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("ToDOT: got\n%s\nwant it to contain %s", got, want)
+		}
+	}
+
+	// a subtree from GetChildren hangs below the root and has no path to highlight
+	sub := Impl.ThreadTree{
+		Root:      Impl.ThreadRoot{ThreadId: "chat"},
+		Messages:  []Impl.Message{{MessageId: "a2", Latest: true}},
+		Relations: []Impl.Triple{{StartId: "u2", Relation: "CHILD", EndId: "a2"}},
+	}
+	got = export.ToDOT(sub, export.GraphOptions{})
+	if !strings.Contains(got, `root -> "a2";`) || strings.Contains(got, "penwidth") {
+		t.Errorf("ToDOT of a subtree: got\n%s", got)
+	}
+}
@@ -0,0 +1,207 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	Impl "github.com/yashbonde/vriksham/impl"
+)
+
+// Branches returns every path from the root to a leaf, the leaves are the ones counted by Breadth. Branches are listed
+// depth first in the order of the relations so branches that share a prefix are next to each other.
+func Branches(tree Impl.ThreadTree) []Impl.Thread {
+	messages := map[string]Impl.Message{}
+	for _, m := range tree.Messages {
+		messages[m.MessageId] = m
+	}
+	children := map[string][]string{}
+	for _, r := range tree.Relations {
+		children[r.StartId] = append(children[r.StartId], r.EndId)
+	}
+
+	branches := []Impl.Thread{}
+	var walk func(path []Impl.Message, id string)
+	walk = func(path []Impl.Message, id string) {
+		m, ok := messages[id]
+		if !ok {
+			return
+		}
+		path = append(path, m)
+		if len(children[id]) == 0 {
+			branches = append(branches, Impl.Thread{Messages: append([]Impl.Message{}, path...)})
+			return
+		}
+		for _, c := range children[id] {
+			walk(path, c)
+		}
+	}
+	for _, id := range children[""] {
+		walk(nil, id)
+	}
+	return branches
+}
+
+// SFT formats, the lines of a fine-tuning file
+const (
+	// SFTOpenAI is the chat format of the OpenAI fine-tuning API, {"messages": [...]}
+	SFTOpenAI = "openai"
+	// SFTShareGPT is {"conversations": [{"from": "human", "value": ...}]} as read by most open source trainers
+	SFTShareGPT = "sharegpt"
+)
+
+type SFTOptions struct {
+	// Format is SFTOpenAI or SFTShareGPT, SFTOpenAI when empty
+	Format string
+
+	// Dedupe trains on every assistant message only once. In the OpenAI format the messages a branch shares with an
+	// earlier one get a weight of 0, ShareGPT has no weights so a branch is only written when it has a new assistant
+	// message at all.
+	Dedupe bool
+
+	// Preferred only writes the branches through the latest message and the ones with a message that has
+	// "preferred": true in its metadata
+	Preferred bool
+}
+
+// OpenAITrainingMessage is a message of the OpenAI fine-tuning format, a weight of 0 leaves it out of the loss
+type OpenAITrainingMessage struct {
+	OpenAIMessage
+	Weight *int `json:"weight,omitempty"`
+}
+
+type OpenAIExample struct {
+	Messages []OpenAITrainingMessage `json:"messages"`
+}
+
+type ShareGPTTurn struct {
+	From  string `json:"from"`
+	Value string `json:"value"`
+}
+
+type ShareGPTExample struct {
+	Id            string         `json:"id"`
+	Conversations []ShareGPTTurn `json:"conversations"`
+}
+
+// shareGPTRoles is the `from` of each role, tool calls are written as function_call turns
+var shareGPTRoles = map[string]string{
+	roleSystem:    "system",
+	roleDeveloper: "system",
+	roleUser:      "human",
+	roleAssistant: "gpt",
+	roleTool:      "observation",
+}
+
+/*
+WriteSFT writes a JSON line for every branch of `tree` to `w` and returns how many it wrote. Branches without an
+assistant message have nothing to train on and are skipped. Call it once for every thread to build a file over many
+of them.
+*/
+func WriteSFT(w io.Writer, tree Impl.ThreadTree, opts SFTOptions) (int, error) {
+	if opts.Format == "" {
+		opts.Format = SFTOpenAI
+	} else if opts.Format != SFTOpenAI && opts.Format != SFTShareGPT {
+		return 0, fmt.Errorf("unknown SFT format %q", opts.Format)
+	}
+	enc := json.NewEncoder(w)
+	trained := map[string]bool{}
+	written := 0
+	for _, branch := range Branches(tree) {
+		if opts.Preferred && !preferred(branch) {
+			continue
+		}
+		// fresh lists the assistant messages of the branch no earlier branch trained on
+		fresh := map[string]bool{}
+		for _, m := range branch.Messages {
+			if r, _ := role(m); r == roleAssistant && !trained[m.MessageId] {
+				fresh[m.MessageId] = true
+			}
+		}
+		if len(fresh) == 0 && (opts.Dedupe || !hasAssistant(branch)) {
+			continue
+		}
+
+		var example any
+		var err error
+		if opts.Format == SFTOpenAI {
+			example, err = openAIExample(branch, fresh, opts.Dedupe)
+		} else {
+			example, err = shareGPTExample(tree.Root.ThreadId, branch)
+		}
+		if err != nil {
+			return written, err
+		}
+		if err := enc.Encode(example); err != nil {
+			return written, err
+		}
+		for id := range fresh {
+			trained[id] = true
+		}
+		written++
+	}
+	return written, nil
+}
+
+func preferred(branch Impl.Thread) bool {
+	for _, m := range branch.Messages {
+		if p, _ := m.Metadata["preferred"].(bool); m.Latest || p {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAssistant(branch Impl.Thread) bool {
+	for _, m := range branch.Messages {
+		if r, _ := role(m); r == roleAssistant {
+			return true
+		}
+	}
+	return false
+}
+
+func openAIExample(branch Impl.Thread, fresh map[string]bool, dedupe bool) (OpenAIExample, error) {
+	messages, err := ToOpenAI(branch)
+	if err != nil {
+		return OpenAIExample{}, err
+	}
+	zero := 0
+	example := OpenAIExample{Messages: make([]OpenAITrainingMessage, len(messages))}
+	for i, m := range messages {
+		example.Messages[i].OpenAIMessage = m
+		if dedupe && m.Role == roleAssistant && !fresh[branch.Messages[i].MessageId] {
+			example.Messages[i].Weight = &zero
+		}
+	}
+	return example, nil
+}
+
+func shareGPTExample(threadId string, branch Impl.Thread) (ShareGPTExample, error) {
+	leaf := branch.Messages[len(branch.Messages)-1]
+	example := ShareGPTExample{Id: threadId + "/" + leaf.MessageId, Conversations: []ShareGPTTurn{}}
+	for _, m := range branch.Messages {
+		r, err := role(m)
+		if err != nil {
+			return ShareGPTExample{}, err
+		}
+		if r != roleAssistant || m.Content != "" {
+			example.Conversations = append(example.Conversations, ShareGPTTurn{From: shareGPTRoles[r], Value: m.Content})
+		}
+		if r != roleAssistant {
+			continue
+		}
+		calls, err := ToolCalls(m)
+		if err != nil {
+			return ShareGPTExample{}, err
+		}
+		for _, call := range calls {
+			value, err := json.Marshal(map[string]any{"name": call.Name, "arguments": call.Arguments})
+			if err != nil {
+				return ShareGPTExample{}, err
+			}
+			example.Conversations = append(example.Conversations, ShareGPTTurn{From: "function_call", Value: string(value)})
+		}
+	}
+	return example, nil
+}
@@ -0,0 +1,110 @@
+package export_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/yashbonde/vriksham/export"
+	Impl "github.com/yashbonde/vriksham/impl"
+)
+
+// chatTree has an answer that was regenerated (a1b), a second answer that was regenerated (a2b) and a question that
+// was never answered (u3):
+//
+//	s ─ u1 ─ a1 ─ u2 ─ a2 (latest)
+//	  │    │         ╰ a2b
+//	  │    ╰ a1b
+//	  ╰ u3
+func chatTree() Impl.ThreadTree {
+	return Impl.ThreadTree{
+		Root: Impl.ThreadRoot{ThreadId: "chat"},
+		Messages: []Impl.Message{
+			{MessageId: "s", Role: "system", Content: "Be brief."},
+			{MessageId: "u1", Role: "user", Content: "Hi"},
+			{MessageId: "a1", Role: "assistant", Content: "Hello!"},
+			{MessageId: "u2", Role: "user", Content: "Bye"},
+			{MessageId: "a2", Role: "assistant", Content: "Bye!", Latest: true},
+			{MessageId: "a2b", Role: "assistant", Content: "See you."},
+			{MessageId: "a1b", Role: "assistant", Content: "Hey."},
+			{MessageId: "u3", Role: "user", Content: "Anyone?"},
+		},
+		Relations: []Impl.Triple{
+			{Relation: "CHILD", EndId: "s"},
+			{StartId: "s", Relation: "CHILD", EndId: "u1"},
+			{StartId: "u1", Relation: "CHILD", EndId: "a1"},
+			{StartId: "a1", Relation: "CHILD", EndId: "u2"},
+			{StartId: "u2", Relation: "CHILD", EndId: "a2"},
+			{StartId: "u2", Relation: "CHILD", EndId: "a2b"},
+			{StartId: "u1", Relation: "CHILD", EndId: "a1b"},
+			{StartId: "s", Relation: "CHILD", EndId: "u3"},
+		},
+	}
+}
+
+func TestBranches(t *testing.T) {
+	branches := export.Branches(*Impl.GetDemoTree())
+	if len(branches) != 9 {
+		t.Fatalf("Branches: got %d branches of the demo tree, want 9", len(branches))
+	}
+	leaves := []string{}
+	for _, b := range branches {
+		leaves = append(leaves, b.Messages[len(b.Messages)-1].MessageId)
+	}
+	expectJSON(t, "Branches", leaves, `["msg_25", "msg_27", "msg_19", "msg_21", "msg_07", "msg_08", "msg_09", "msg_10", "msg_13"]`)
+	if got := len(branches[1].Messages); got != 8 {
+		t.Errorf("Branches: got %d messages upto msg_27, want 8", got)
+	}
+}
+
+// sft writes the examples of chatTree and returns them one per line
+func sft(t *testing.T, opts export.SFTOptions) []string {
+	t.Helper()
+	b := &bytes.Buffer{}
+	n, err := export.WriteSFT(b, chatTree(), opts)
+	if err != nil {
+		t.Fatalf("WriteSFT(%+v): %v", opts, err)
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if n != len(lines) {
+		t.Errorf("WriteSFT(%+v): returned %d for %d lines", opts, n, len(lines))
+	}
+	return lines
+}
+
+func TestWriteSFT(t *testing.T) {
+	lines := sft(t, export.SFTOptions{})
+	if len(lines) != 3 {
+		t.Fatalf("WriteSFT: got %d examples, want 3 since u3 has no answer", len(lines))
+	}
+	expectJSON(t, "WriteSFT", json.RawMessage(lines[2]), `{"messages": [
+		{"role": "system", "content": "Be brief."},
+		{"role": "user", "content": "Hi"},
+		{"role": "assistant", "content": "Hey."}
+	]}`)
+
+	lines = sft(t, export.SFTOptions{Dedupe: true})
+	expectJSON(t, "WriteSFT with Dedupe", json.RawMessage(lines[1]), `{"messages": [
+		{"role": "system", "content": "Be brief."},
+		{"role": "user", "content": "Hi"},
+		{"role": "assistant", "content": "Hello!", "weight": 0},
+		{"role": "user", "content": "Bye"},
+		{"role": "assistant", "content": "See you."}
+	]}`)
+
+	if lines = sft(t, export.SFTOptions{Preferred: true}); len(lines) != 1 || !strings.Contains(lines[0], "Bye!") {
+		t.Errorf("WriteSFT with Preferred: got %q, want the branch to the latest message", lines)
+	}
+
+	lines = sft(t, export.SFTOptions{Format: export.SFTShareGPT})
+	expectJSON(t, "WriteSFT as ShareGPT", json.RawMessage(lines[2]), `{"id": "chat/a1b", "conversations": [
+		{"from": "system", "value": "Be brief."},
+		{"from": "human", "value": "Hi"},
+		{"from": "gpt", "value": "Hey."}
+	]}`)
+
+	if _, err := export.WriteSFT(&bytes.Buffer{}, chatTree(), export.SFTOptions{Format: "alpaca"}); err == nil {
+		t.Errorf("WriteSFT: expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,775 @@
+package impl
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+/*
+Backend_Bolt stores threads in a single bbolt file, which makes it a good fit for desktop apps that keep the chat
+history on disk. Every thread is a bucket inside `threads` with these buckets in it:
+
+- messages: message id -> JSON encoded Message
+- parents:  message id -> parent message id, empty for the children of the ThreadRoot
+- children: parent id + "\x00" + child id -> nothing, so the children of a node are a prefix scan
+- meta:     "latest" -> id of the latest message, "created" and "updated" -> oldest and newest created_at
+
+Next to `threads` the `activity` bucket has a key for every thread that sorts the newest "updated" first, so
+ListThreads pages through either bucket with a cursor. Pick follows the parent pointers and GetChildren scans the
+children index, neither looks at the rest of the thread.
+*/
+type Backend_Bolt struct {
+	Path string `json:"path"`
+	db   *bolt.DB
+}
+
+var (
+	boltThreads   = []byte("threads")
+	boltMessages  = []byte("messages")
+	boltParents   = []byte("parents")
+	boltChildren  = []byte("children")
+	boltMeta      = []byte("meta")
+	boltLatestKey = []byte("latest")
+
+	boltActivity   = []byte("activity")
+	boltCreatedKey = []byte("created")
+	boltUpdatedKey = []byte("updated")
+)
+
+func init() {
+	Register("bbolt", func(ctx context.Context, u *url.URL) (TreeEngine, error) {
+		path, err := filePath(u)
+		if err != nil {
+			return nil, err
+		}
+		backend := &Backend_Bolt{Path: path}
+		return backend, backend.Connect(ctx)
+	})
+}
+
+func (backend *Backend_Bolt) Connect(ctx context.Context) error {
+	db, err := bolt.Open(backend.Path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		threads, err := tx.CreateBucketIfNotExists(boltThreads)
+		if err != nil || tx.Bucket(boltActivity) != nil {
+			return err
+		}
+		// files written before the activity index get it here
+		if _, err := tx.CreateBucket(boltActivity); err != nil {
+			return err
+		}
+		threadIds := []string{}
+		threads.ForEachBucket(func(k []byte) error {
+			threadIds = append(threadIds, string(k))
+			return nil
+		})
+		for _, threadId := range threadIds {
+			if err := boltGetThread(tx, threadId).retime(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return err
+	}
+	backend.db = db
+	return nil
+}
+
+func (backend *Backend_Bolt) Close() error {
+	if backend.db == nil {
+		return nil
+	}
+	err := backend.db.Close()
+	backend.db = nil
+	return err
+}
+
+// connected is ErrNotConnected before Connect and after Close, every method checks it before using the file
+func (db Backend_Bolt) connected() error {
+	if db.db == nil {
+		return ErrNotConnected
+	}
+	return nil
+}
+
+// Ping only checks the file is open, bbolt has nothing to reach
+func (db Backend_Bolt) Ping(ctx context.Context) error {
+	if err := db.connected(); err != nil {
+		return err
+	}
+	return db.db.View(func(tx *bolt.Tx) error { return nil })
+}
+
+// Info reports the version of bbolt, the file is locked by the process that opened it so it is not shared
+func (db Backend_Bolt) Info(ctx context.Context) (BackendInfo, error) {
+	info := BackendInfo{Name: "bolt", Version: moduleVersion("go.etcd.io/bbolt"), Capabilities: []string{CapabilityPersistent}}
+	if err := db.connected(); err != nil {
+		return info, err
+	}
+	return info, nil
+}
+
+// boltThread wraps the buckets of a single thread
+type boltThread struct {
+	id       string
+	messages *bolt.Bucket
+	parents  *bolt.Bucket
+	children *bolt.Bucket
+	meta     *bolt.Bucket
+	activity *bolt.Bucket // the top level one
+}
+
+// boltGetThread returns the buckets for `threadId`, nil when the thread does not exist
+func boltGetThread(tx *bolt.Tx, threadId string) *boltThread {
+	if threadId == "" {
+		return nil
+	}
+	b := tx.Bucket(boltThreads).Bucket([]byte(threadId))
+	if b == nil {
+		return nil
+	}
+	return &boltThread{
+		id:       threadId,
+		messages: b.Bucket(boltMessages),
+		parents:  b.Bucket(boltParents),
+		children: b.Bucket(boltChildren),
+		meta:     b.Bucket(boltMeta),
+		activity: tx.Bucket(boltActivity),
+	}
+}
+
+func boltCreateThread(tx *bolt.Tx, threadId string) (*boltThread, error) {
+	if threadId == "" {
+		return nil, fmt.Errorf("threadId cannot be empty")
+	}
+	if t := boltGetThread(tx, threadId); t != nil {
+		return t, nil
+	}
+	b, err := tx.Bucket(boltThreads).CreateBucket([]byte(threadId))
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range [][]byte{boltMessages, boltParents, boltChildren, boltMeta} {
+		if _, err := b.CreateBucket(name); err != nil {
+			return nil, err
+		}
+	}
+	// a thread without times is still listed by activity, after the others
+	t := boltGetThread(tx, threadId)
+	return t, t.setTimes(time.Time{}, time.Time{})
+}
+
+// boltActivityKey sorts the threads by their newest created_at, newest first and the ones without one last
+func boltActivityKey(updated time.Time, threadId string) []byte {
+	key := make([]byte, 8, 8+len(threadId))
+	n := uint64(math.MaxUint64)
+	if !updated.IsZero() {
+		// flipping the sign bit orders the signed nanoseconds as unsigned, inverting it puts the newest first
+		n = ^(uint64(updated.UnixNano()) ^ 1<<63)
+	}
+	binary.BigEndian.PutUint64(key, n)
+	return append(key, threadId...)
+}
+
+func boltChildKey(parentId, childId string) []byte {
+	return []byte(parentId + "\x00" + childId)
+}
+
+func (t *boltThread) message(id string) (Message, bool, error) {
+	m := Message{}
+	data := t.messages.Get([]byte(id))
+	if data == nil {
+		return m, false, nil
+	}
+	err := json.Unmarshal(data, &m)
+	return m, true, err
+}
+
+func (t *boltThread) putMessage(m Message) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return t.messages.Put([]byte(m.MessageId), data)
+}
+
+func (t *boltThread) parent(id string) (string, bool) {
+	p := t.parents.Get([]byte(id))
+	return string(p), p != nil
+}
+
+func (t *boltThread) childIds(parentId string) []string {
+	output := []string{}
+	prefix := boltChildKey(parentId, "")
+	c := t.children.Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		output = append(output, string(k[len(prefix):]))
+	}
+	return output
+}
+
+func (t *boltThread) add(m Message, parentId string) error {
+	if m.Latest {
+		if _, err := t.setLatest(m); err != nil {
+			return err
+		}
+	} else if err := t.putMessage(m); err != nil {
+		return err
+	}
+	if err := t.touch(m.CreatedAt); err != nil {
+		return err
+	}
+	if err := t.parents.Put([]byte(m.MessageId), []byte(parentId)); err != nil {
+		return err
+	}
+	return t.children.Put(boltChildKey(parentId, m.MessageId), []byte{})
+}
+
+// times are the oldest and newest created_at of the messages, zero when none has one
+func (t *boltThread) times() (created, updated time.Time) {
+	created, _ = time.Parse(time.RFC3339Nano, string(t.meta.Get(boltCreatedKey)))
+	updated, _ = time.Parse(time.RFC3339Nano, string(t.meta.Get(boltUpdatedKey)))
+	return created, updated
+}
+
+// setTimes stores the times and moves the key of the thread in the activity index
+func (t *boltThread) setTimes(created, updated time.Time) error {
+	_, old := t.times()
+	if err := t.activity.Delete(boltActivityKey(old, t.id)); err != nil {
+		return err
+	}
+	for key, value := range map[string]time.Time{string(boltCreatedKey): created, string(boltUpdatedKey): updated} {
+		var err error
+		if value.IsZero() {
+			err = t.meta.Delete([]byte(key))
+		} else {
+			err = t.meta.Put([]byte(key), []byte(value.Format(time.RFC3339Nano)))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return t.activity.Put(boltActivityKey(updated, t.id), []byte{})
+}
+
+// touch moves the times of the thread out to include `createdAt`
+func (t *boltThread) touch(createdAt time.Time) error {
+	created, updated := t.times()
+	if createdAt.IsZero() || (!createdAt.Before(created) && !createdAt.After(updated)) {
+		return nil
+	}
+	if created.IsZero() || createdAt.Before(created) {
+		created = createdAt
+	}
+	if createdAt.After(updated) {
+		updated = createdAt
+	}
+	return t.setTimes(created, updated)
+}
+
+// retime sets the times of the thread from its messages, after some of them were deleted
+func (t *boltThread) retime() error {
+	var created, updated time.Time
+	err := t.messages.ForEach(func(_, data []byte) error {
+		m := struct {
+			CreatedAt time.Time `json:"created_at"`
+		}{}
+		if err := json.Unmarshal(data, &m); err != nil || m.CreatedAt.IsZero() {
+			return err
+		}
+		if created.IsZero() || m.CreatedAt.Before(created) {
+			created = m.CreatedAt
+		}
+		if m.CreatedAt.After(updated) {
+			updated = m.CreatedAt
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return t.setTimes(created, updated)
+}
+
+// walk visits the nodes below `startId` breadth first, upto `levels` levels deep (-1 for no limit)
+func (t *boltThread) walk(startId string, levels int, visit func(parentId, childId string) error) error {
+	frontier := []string{startId}
+	for level := 0; len(frontier) > 0 && (levels < 0 || level < levels); level++ {
+		next := []string{}
+		for _, p := range frontier {
+			for _, c := range t.childIds(p) {
+				if err := visit(p, c); err != nil {
+					return err
+				}
+				next = append(next, c)
+			}
+		}
+		frontier = next
+	}
+	return nil
+}
+
+// subtree collects the messages below `startId` along with their relations
+func (t *boltThread) subtree(startId string, levels int, output *ThreadTree) error {
+	return t.walk(startId, levels, func(p, c string) error {
+		m, _, err := t.message(c)
+		if err != nil {
+			return err
+		}
+		output.Messages = append(output.Messages, m)
+		output.Relations = append(output.Relations, Triple{StartId: p, Relation: "CHILD", EndId: c})
+		return nil
+	})
+}
+
+func (t *boltThread) depth() int {
+	output := 0
+	frontier := t.childIds("")
+	for len(frontier) > 0 {
+		output++
+		next := []string{}
+		for _, c := range frontier {
+			next = append(next, t.childIds(c)...)
+		}
+		frontier = next
+	}
+	return output
+}
+
+func (t *boltThread) latest() (Message, bool, error) {
+	id := t.meta.Get(boltLatestKey)
+	if id == nil {
+		return Message{}, false, nil
+	}
+	return t.message(string(id))
+}
+
+// setLatest stores `m` as the latest message, the message that was the latest before loses its flag
+func (t *boltThread) setLatest(m Message) (Message, error) {
+	old, ok, err := t.latest()
+	if err != nil {
+		return m, err
+	} else if ok && old.MessageId != m.MessageId {
+		old.Latest = false
+		if err := t.putMessage(old); err != nil {
+			return m, err
+		}
+	}
+	m.Latest = true
+	if err := t.putMessage(m); err != nil {
+		return m, err
+	}
+	return m, t.meta.Put(boltLatestKey, []byte(m.MessageId))
+}
+
+// byId returns the stored message `id`, or the error to return when it is missing
+func (t *boltThread) byId(threadId, id string) (Message, error) {
+	m, ok, err := t.message(id)
+	if err == nil && !ok {
+		err = messageNotFound(threadId, id)
+	}
+	return m, err
+}
+
+// view runs `fn` on the thread in a read only transaction
+func (db Backend_Bolt) view(threadId string, fn func(t *boltThread) error) error {
+	if err := db.connected(); err != nil {
+		return err
+	}
+	return db.db.View(func(tx *bolt.Tx) error {
+		t := boltGetThread(tx, threadId)
+		if t == nil {
+			return threadNotFound(threadId)
+		}
+		return fn(t)
+	})
+}
+
+// update runs `fn` on the thread in a read write transaction
+func (db Backend_Bolt) update(threadId string, fn func(tx *bolt.Tx, t *boltThread) error) error {
+	if err := db.connected(); err != nil {
+		return err
+	}
+	return db.db.Update(func(tx *bolt.Tx) error {
+		t := boltGetThread(tx, threadId)
+		if t == nil {
+			return threadNotFound(threadId)
+		}
+		return fn(tx, t)
+	})
+}
+
+// implement interface
+
+func (db Backend_Bolt) AddMessage(threadId string, a, b *Message, ctx context.Context) error {
+	if a == nil || a.MessageId == "" {
+		return fmt.Errorf("%w: message to be inserted cannot be empty", ErrInvalidMessage)
+	}
+	return db.update(threadId, func(_ *bolt.Tx, t *boltThread) error {
+		parentId := ""
+		if b != nil {
+			if _, err := t.byId(threadId, b.MessageId); err != nil {
+				return err
+			}
+			parentId = b.MessageId
+		}
+		if _, ok := t.parent(a.MessageId); ok {
+			return duplicateMessage(threadId, a.MessageId)
+		}
+		// the latest message is only changed through SetLatestMessage
+		payload := *a
+		payload.Latest = false
+		return t.add(payload, parentId)
+	})
+}
+
+func (db Backend_Bolt) AddTree(threadId string, tree ThreadTree, ctx context.Context) error {
+	if err := db.connected(); err != nil {
+		return err
+	}
+	if err := validateTree(threadId, tree); err != nil {
+		return err
+	}
+
+	// a returned error rolls back the whole transaction
+	return db.db.Update(func(tx *bolt.Tx) error {
+		t, err := boltCreateThread(tx, threadId)
+		if err != nil {
+			return err
+		}
+		messages := map[string]Message{}
+		for _, m := range tree.Messages {
+			messages[m.MessageId] = m
+		}
+		for _, r := range tree.Relations {
+			_, inTree := messages[r.StartId]
+			_, stored := t.parent(r.StartId)
+			if r.StartId != "" && !inTree && !stored {
+				return invalidTree("relation starts from unknown message %s", r.StartId)
+			}
+			m, inTree := messages[r.EndId]
+			p, stored := t.parent(r.EndId)
+			if !inTree && !stored {
+				return invalidTree("relation ends at unknown message %s", r.EndId)
+			} else if stored && p != r.StartId {
+				return invalidTree("message %s already has a parent", r.EndId)
+			} else if stored {
+				continue
+			}
+			if err := t.add(m, r.StartId); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (db Backend_Bolt) Breadth(threadId string, ctx context.Context) (int, error) {
+	output := 0
+	err := db.view(threadId, func(t *boltThread) error {
+		return t.walk("", -1, func(_, c string) error {
+			if len(t.childIds(c)) == 0 {
+				output++
+			}
+			return nil
+		})
+	})
+	return output, err
+}
+
+func (db Backend_Bolt) Degree(threadId string, message *Message, ctx context.Context) (int, error) {
+	output := 0
+	err := db.view(threadId, func(t *boltThread) error {
+		startId := ""
+		if message != nil {
+			if _, err := t.byId(threadId, message.MessageId); err != nil {
+				return err
+			}
+			startId = message.MessageId
+		}
+		output = len(t.childIds(startId))
+		return nil
+	})
+	return output, err
+}
+
+func (db Backend_Bolt) Delete(threadId string, message *Message, ctx context.Context) error {
+	return db.update(threadId, func(tx *bolt.Tx, t *boltThread) error {
+		if message == nil {
+			_, updated := t.times()
+			if err := t.activity.Delete(boltActivityKey(updated, threadId)); err != nil {
+				return err
+			}
+			return tx.Bucket(boltThreads).DeleteBucket([]byte(threadId))
+		}
+		if _, err := t.byId(threadId, message.MessageId); err != nil {
+			return err
+		}
+
+		removed := []string{message.MessageId}
+		if err := t.walk(message.MessageId, -1, func(_, c string) error {
+			removed = append(removed, c)
+			return nil
+		}); err != nil {
+			return err
+		}
+		latest := string(t.meta.Get(boltLatestKey))
+		for _, id := range removed {
+			p, _ := t.parent(id)
+			for _, err := range []error{
+				t.children.Delete(boltChildKey(p, id)),
+				t.parents.Delete([]byte(id)),
+				t.messages.Delete([]byte(id)),
+			} {
+				if err != nil {
+					return err
+				}
+			}
+			if id == latest {
+				if err := t.meta.Delete(boltLatestKey); err != nil {
+					return err
+				}
+			}
+		}
+		return t.retime()
+	})
+}
+
+func (db Backend_Bolt) Depth(threadId string, ctx context.Context) (int, error) {
+	output := 0
+	err := db.view(threadId, func(t *boltThread) error {
+		output = t.depth()
+		return nil
+	})
+	return output, err
+}
+
+func (db Backend_Bolt) Get(threadId string, ctx context.Context) (ThreadTree, error) {
+	output := ThreadTree{}
+	err := db.view(threadId, func(t *boltThread) error {
+		output.Root = ThreadRoot{ThreadId: threadId}
+		return t.subtree("", -1, &output)
+	})
+	if err != nil {
+		return ThreadTree{}, err
+	}
+	return output, nil
+}
+
+func (db Backend_Bolt) GetChildren(threadId string, message *Message, depth int, ctx context.Context) (ThreadTree, error) {
+	output := ThreadTree{}
+	if err := validateDepth(depth); err != nil {
+		return output, err
+	} else if depth == 1 {
+		depth = 2
+	}
+	err := db.view(threadId, func(t *boltThread) error {
+		startId := ""
+		if message != nil {
+			m, err := t.byId(threadId, message.MessageId)
+			if err != nil {
+				return err
+			}
+			startId = m.MessageId
+			output.Messages = append(output.Messages, m)
+		}
+		output.Root = ThreadRoot{ThreadId: threadId}
+		return t.subtree(startId, depth-1, &output)
+	})
+	if err != nil {
+		return ThreadTree{}, err
+	}
+	return output, nil
+}
+
+func (db Backend_Bolt) GetLatestMessage(threadId string, ctx context.Context) (Message, error) {
+	output := Message{}
+	err := db.view(threadId, func(t *boltThread) error {
+		m, ok, err := t.latest()
+		if err == nil && !ok {
+			err = ErrNoLatest
+		}
+		output = m
+		return err
+	})
+	return output, err
+}
+
+func (db Backend_Bolt) Pick(threadId string, a *Message, b *Message, ctx context.Context) (Thread, error) {
+	output := Thread{}
+	err := db.view(threadId, func(t *boltThread) error {
+		startId := ""
+		if a != nil {
+			if _, err := t.byId(threadId, a.MessageId); err != nil {
+				return err
+			}
+			startId = a.MessageId
+		}
+		var end Message
+		var err error
+		if b == nil {
+			var ok bool
+			end, ok, err = t.latest()
+			if err == nil && !ok {
+				err = ErrNoLatest
+			}
+		} else {
+			end, err = t.byId(threadId, b.MessageId)
+		}
+		if err != nil {
+			return err
+		}
+
+		// walk up the parents from the end till we hit the start
+		path := []Message{end}
+		for id := end.MessageId; ; {
+			parentId, ok := t.parent(id)
+			if ok && parentId == startId {
+				break
+			} else if !ok || parentId == "" {
+				// the start is not above the end
+				return nil
+			}
+			m, err := t.byId(threadId, parentId)
+			if err != nil {
+				return err
+			}
+			path = append(path, m)
+			id = parentId
+		}
+		hops := len(path)
+		if a != nil {
+			m, err := t.byId(threadId, startId)
+			if err != nil {
+				return err
+			}
+			path = append(path, m)
+		}
+		if hops > maxPickLength {
+			return pickTooLong()
+		}
+		for i := len(path) - 1; i >= 0; i-- {
+			output.Messages = append(output.Messages, path[i])
+		}
+		return nil
+	})
+	return output, err
+}
+
+func (db Backend_Bolt) SetLatestMessage(threadId string, latestMessage *Message, ctx context.Context) (Message, error) {
+	output := Message{}
+	if latestMessage == nil {
+		return output, fmt.Errorf("%w: latest message cannot be empty", ErrInvalidMessage)
+	}
+	err := db.update(threadId, func(_ *bolt.Tx, t *boltThread) error {
+		m, err := t.byId(threadId, latestMessage.MessageId)
+		if err != nil {
+			return err
+		}
+		output, err = t.setLatest(m)
+		return err
+	})
+	return output, err
+}
+
+func (db Backend_Bolt) Size(threadId string, ctx context.Context) (int, error) {
+	output := 0
+	err := db.view(threadId, func(t *boltThread) error {
+		output = t.parents.Stats().KeyN
+		return nil
+	})
+	return output, err
+}
+
+// ListThreads runs a cursor over the `threads` bucket or the `activity` index from where the last page ended, only a
+// metadata filter has to decode the messages of the threads it passes
+func (db Backend_Bolt) ListThreads(opts ListOptions, ctx context.Context) (ThreadPage, error) {
+	if err := db.connected(); err != nil {
+		return ThreadPage{}, err
+	}
+	q, err := newListQuery(opts)
+	if err != nil {
+		return ThreadPage{}, err
+	}
+	threads := []ThreadSummary{}
+	err = db.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltThreads).Cursor()
+		threadId := func(k []byte) string { return string(k) }
+		var start []byte
+		if q.OrderBy == ListByActivity {
+			c = tx.Bucket(boltActivity).Cursor()
+			threadId = func(k []byte) string { return string(k[8:]) }
+			if q.after != nil {
+				start = boltActivityKey(q.after.UpdatedAt, q.after.ThreadId)
+			}
+		} else if q.after != nil {
+			start = []byte(q.after.ThreadId)
+		}
+
+		k, _ := c.First()
+		if start != nil {
+			if k, _ = c.Seek(start); bytes.Equal(k, start) {
+				k, _ = c.Next()
+			}
+		}
+		for ; k != nil && len(threads) <= q.Limit; k, _ = c.Next() {
+			// the keys of `threads` that are not buckets are skipped
+			t := boltGetThread(tx, threadId(k))
+			if t == nil {
+				continue
+			}
+			if ok, err := t.matches(q.Metadata); err != nil {
+				return err
+			} else if !ok {
+				continue
+			}
+			s := ThreadSummary{ThreadId: t.id, Size: t.parents.Stats().KeyN, Depth: t.depth()}
+			s.CreatedAt, s.UpdatedAt = t.times()
+			latest, ok, err := t.latest()
+			if err != nil {
+				return err
+			} else if ok {
+				s.Latest = &latest
+			}
+			threads = append(threads, s)
+		}
+		return nil
+	})
+	if err != nil {
+		return ThreadPage{}, err
+	}
+	return q.page(threads), nil
+}
+
+// matches tells if a message of the thread passes the metadata filter
+func (t *boltThread) matches(metadata map[string]any) (bool, error) {
+	if len(metadata) == 0 {
+		return true, nil
+	}
+	c := t.messages.Cursor()
+	for k, data := c.First(); k != nil; k, data = c.Next() {
+		m := struct {
+			Metadata map[string]any `json:"metadata"`
+		}{}
+		if err := json.Unmarshal(data, &m); err != nil {
+			return false, err
+		} else if metadataMatches(m.Metadata, metadata) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
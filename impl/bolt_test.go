@@ -0,0 +1,60 @@
+package impl_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	Impl "github.com/yashbonde/vriksham/impl"
+	"github.com/yashbonde/vriksham/impl/enginetest"
+)
+
+func TestBackendBolt(t *testing.T) {
+	enginetest.Run(t, func(t *testing.T) Impl.TreeEngine {
+		backend := &Impl.Backend_Bolt{Path: filepath.Join(t.TempDir(), "vriksham.bolt")}
+		if err := backend.Connect(context.Background()); err != nil {
+			t.Fatalf("Connect: %v", err)
+		}
+		return backend
+	})
+}
+
+// a merged tree that brings its own latest message takes the flag from the stored one
+func TestAddTreeLatestBolt(t *testing.T) {
+	ctx := context.Background()
+	backend := &Impl.Backend_Bolt{Path: filepath.Join(t.TempDir(), "vriksham.bolt")}
+	if err := backend.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer backend.Close()
+	tree := *Impl.GetDemoTree()
+	threadId := tree.Root.ThreadId
+	if err := backend.AddTree(threadId, tree, ctx); err != nil {
+		t.Fatalf("AddTree: %v", err)
+	}
+	if _, err := backend.SetLatestMessage(threadId, &Impl.Message{MessageId: "msg_27"}, ctx); err != nil {
+		t.Fatalf("SetLatestMessage: %v", err)
+	}
+	merged := Impl.ThreadTree{
+		Root:      tree.Root,
+		Messages:  []Impl.Message{{MessageId: "new_00", Latest: true}},
+		Relations: []Impl.Triple{{StartId: "msg_27", Relation: "CHILD", EndId: "new_00"}},
+	}
+	if err := backend.AddTree(threadId, merged, ctx); err != nil {
+		t.Fatalf("AddTree(merged): %v", err)
+	}
+
+	stored, err := backend.Get(threadId, ctx)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	latest := []string{}
+	for _, m := range stored.Messages {
+		if m.Latest {
+			latest = append(latest, m.MessageId)
+		}
+	}
+	if len(latest) != 1 || latest[0] != "new_00" {
+		t.Errorf("Get after merging a latest message: got %v latest, want [new_00]", latest)
+	}
+}
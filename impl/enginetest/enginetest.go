@@ -0,0 +1,719 @@
+/*
+Package enginetest is the conformance suite for TreeEngine backends. Every backend runs the same checks against the
+demo tree so they all agree on what each method returns.
+
+	func TestMyBackend(t *testing.T) {
+		enginetest.Run(t, func(t *testing.T) impl.TreeEngine {
+			return &MyBackend{}
+		})
+	}
+
+The factory is called once per subtest and must return an engine that does not contain the demo thread.
+*/
+package enginetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	Impl "github.com/yashbonde/vriksham/impl"
+)
+
+// Factory returns a fresh engine for a single subtest
+type Factory func(t *testing.T) Impl.TreeEngine
+
+// Run executes the conformance suite against the engines returned by `factory`
+func Run(t *testing.T, factory Factory) {
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			engine := factory(t)
+			demo := Impl.GetDemoTree()
+			if err := engine.AddTree(demo.Root.ThreadId, *demo, ctx); err != nil {
+				t.Fatalf("AddTree(demo): %v", err)
+			}
+			t.Cleanup(func() { engine.Delete(demo.Root.ThreadId, nil, context.Background()) })
+			tc.run(t, engine, demo.Root.ThreadId, ctx)
+		})
+	}
+}
+
+type testCase struct {
+	name string
+	run  func(t *testing.T, engine Impl.TreeEngine, threadId string, ctx context.Context)
+}
+
+// messages on the path from the root to msg_27, which is the latest message of the demo tree
+var latestPath = []string{"msg_00", "msg_06", "msg_14", "msg_15", "msg_22", "msg_23", "msg_26", "msg_27"}
+
+var cases = []testCase{
+	{"Size", func(t *testing.T, engine Impl.TreeEngine, threadId string, ctx context.Context) {
+		// the ThreadRoot is not counted
+		expectInt(t, "Size", 28)(engine.Size(threadId, ctx))
+		_, err := engine.Size("unknown", ctx)
+		expectErr(t, "Size(unknown)", err, Impl.ErrThreadNotFound)
+	}},
+	{"Breadth", func(t *testing.T, engine Impl.TreeEngine, threadId string, ctx context.Context) {
+		expectInt(t, "Breadth", 9)(engine.Breadth(threadId, ctx))
+		_, err := engine.Breadth("unknown", ctx)
+		expectErr(t, "Breadth(unknown)", err, Impl.ErrThreadNotFound)
+	}},
+	{"Depth", func(t *testing.T, engine Impl.TreeEngine, threadId string, ctx context.Context) {
+		// root -> msg_00 -> msg_06 -> msg_14 -> msg_15 -> msg_22 -> msg_23 -> msg_24 -> msg_25
+		expectInt(t, "Depth", 8)(engine.Depth(threadId, ctx))
+		_, err := engine.Depth("unknown", ctx)
+		expectErr(t, "Depth(unknown)", err, Impl.ErrThreadNotFound)
+	}},
+	{"Degree", func(t *testing.T, engine Impl.TreeEngine, threadId string, ctx context.Context) {
+		expectInt(t, "Degree(root)", 6)(engine.Degree(threadId, nil, ctx))
+		expectInt(t, "Degree(msg_06)", 2)(engine.Degree(threadId, msg("msg_06"), ctx))
+		expectInt(t, "Degree(msg_23)", 2)(engine.Degree(threadId, msg("msg_23"), ctx))
+		expectInt(t, "Degree(msg_27)", 0)(engine.Degree(threadId, msg("msg_27"), ctx))
+
+		_, err := engine.Degree(threadId, msg("unknown"), ctx)
+		expectErr(t, "Degree(unknown)", err, Impl.ErrMessageNotFound)
+		_, err = engine.Degree("unknown", nil, ctx)
+		expectErr(t, "Degree(unknown thread)", err, Impl.ErrThreadNotFound)
+	}},
+	{"Get", func(t *testing.T, engine Impl.TreeEngine, threadId string, ctx context.Context) {
+		tree, err := engine.Get(threadId, ctx)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		demo := Impl.GetDemoTree()
+		if tree.Root.ThreadId != threadId {
+			t.Errorf("Get: root = %q, want %q", tree.Root.ThreadId, threadId)
+		}
+		expectIds(t, "Get messages", sorted(ids(tree.Messages)), sorted(ids(demo.Messages)))
+		expectRelations(t, "Get relations", tree.Relations, demo.Relations)
+		for _, m := range tree.Messages {
+			if m.Latest != (m.MessageId == "msg_27") {
+				t.Errorf("Get: %s has latest = %v", m.MessageId, m.Latest)
+			}
+		}
+
+		_, err = engine.Get("unknown", ctx)
+		expectErr(t, "Get(unknown)", err, Impl.ErrThreadNotFound)
+	}},
+	{"GetChildren", func(t *testing.T, engine Impl.TreeEngine, threadId string, ctx context.Context) {
+		// depth 1 from the root gives the root level messages
+		tree, err := engine.GetChildren(threadId, nil, 1, ctx)
+		if err != nil {
+			t.Fatalf("GetChildren(root, 1): %v", err)
+		}
+		if tree.Root.ThreadId != threadId {
+			t.Errorf("GetChildren(root, 1): root = %q, want %q", tree.Root.ThreadId, threadId)
+		}
+		expectIds(t, "GetChildren(root, 1) messages", sorted(ids(tree.Messages)),
+			[]string{"msg_00", "msg_01", "msg_02", "msg_03", "msg_04", "msg_05"})
+		expectRelations(t, "GetChildren(root, 1) relations", tree.Relations, []Impl.Triple{
+			{Relation: "CHILD", EndId: "msg_00"},
+			{Relation: "CHILD", EndId: "msg_01"},
+			{Relation: "CHILD", EndId: "msg_02"},
+			{Relation: "CHILD", EndId: "msg_03"},
+			{Relation: "CHILD", EndId: "msg_04"},
+			{Relation: "CHILD", EndId: "msg_05"},
+		})
+
+		// starting from a message includes the message itself
+		tree, err = engine.GetChildren(threadId, msg("msg_06"), 1, ctx)
+		if err != nil {
+			t.Fatalf("GetChildren(msg_06, 1): %v", err)
+		}
+		expectIds(t, "GetChildren(msg_06, 1) messages", sorted(ids(tree.Messages)), []string{"msg_06", "msg_14", "msg_16"})
+		expectRelations(t, "GetChildren(msg_06, 1) relations", tree.Relations, []Impl.Triple{
+			{StartId: "msg_06", Relation: "CHILD", EndId: "msg_14"},
+			{StartId: "msg_06", Relation: "CHILD", EndId: "msg_16"},
+		})
+
+		// depth counts the levels including the starting node
+		tree, err = engine.GetChildren(threadId, msg("msg_06"), 3, ctx)
+		if err != nil {
+			t.Fatalf("GetChildren(msg_06, 3): %v", err)
+		}
+		expectIds(t, "GetChildren(msg_06, 3) messages", sorted(ids(tree.Messages)),
+			[]string{"msg_06", "msg_14", "msg_15", "msg_16", "msg_17"})
+		if len(tree.Relations) != 4 {
+			t.Errorf("GetChildren(msg_06, 3): got %d relations, want 4", len(tree.Relations))
+		}
+
+		// a leaf only has itself
+		tree, err = engine.GetChildren(threadId, msg("msg_27"), 1, ctx)
+		if err != nil {
+			t.Fatalf("GetChildren(msg_27, 1): %v", err)
+		}
+		expectIds(t, "GetChildren(msg_27, 1) messages", ids(tree.Messages), []string{"msg_27"})
+		expectRelations(t, "GetChildren(msg_27, 1) relations", tree.Relations, nil)
+
+		for _, depth := range []int{0, -1, 11} {
+			_, err := engine.GetChildren(threadId, nil, depth, ctx)
+			expectErr(t, fmt.Sprintf("GetChildren(root, %d)", depth), err, Impl.ErrDepthExceeded)
+		}
+		_, err = engine.GetChildren(threadId, msg("unknown"), 1, ctx)
+		expectErr(t, "GetChildren(unknown, 1)", err, Impl.ErrMessageNotFound)
+		_, err = engine.GetChildren("unknown", nil, 1, ctx)
+		expectErr(t, "GetChildren(unknown thread)", err, Impl.ErrThreadNotFound)
+	}},
+	{"GetLatestMessage", func(t *testing.T, engine Impl.TreeEngine, threadId string, ctx context.Context) {
+		m, err := engine.GetLatestMessage(threadId, ctx)
+		if err != nil {
+			t.Fatalf("GetLatestMessage: %v", err)
+		}
+		if m.MessageId != "msg_27" || !m.Latest {
+			t.Errorf("GetLatestMessage: got %+v, want msg_27", m)
+		}
+		_, err = engine.GetLatestMessage("unknown", ctx)
+		expectErr(t, "GetLatestMessage(unknown)", err, Impl.ErrThreadNotFound)
+
+		// a thread where no message was ever marked as the latest one
+		tree := Impl.ThreadTree{
+			Root:      Impl.ThreadRoot{ThreadId: "plain_thread"},
+			Messages:  []Impl.Message{*msg("plain_00")},
+			Relations: []Impl.Triple{{Relation: "CHILD", EndId: "plain_00"}},
+		}
+		if err := engine.AddTree("plain_thread", tree, ctx); err != nil {
+			t.Fatalf("AddTree(plain_thread): %v", err)
+		}
+		defer engine.Delete("plain_thread", nil, ctx)
+		_, err = engine.GetLatestMessage("plain_thread", ctx)
+		expectErr(t, "GetLatestMessage(plain_thread)", err, Impl.ErrNoLatest)
+		_, err = engine.Pick("plain_thread", nil, nil, ctx)
+		expectErr(t, "Pick(plain_thread, latest)", err, Impl.ErrNoLatest)
+	}},
+	{"SetLatestMessage", func(t *testing.T, engine Impl.TreeEngine, threadId string, ctx context.Context) {
+		m, err := engine.SetLatestMessage(threadId, msg("msg_07"), ctx)
+		if err != nil {
+			t.Fatalf("SetLatestMessage(msg_07): %v", err)
+		}
+		if m.MessageId != "msg_07" || !m.Latest {
+			t.Errorf("SetLatestMessage(msg_07): got %+v", m)
+		}
+		m, err = engine.GetLatestMessage(threadId, ctx)
+		if err != nil || m.MessageId != "msg_07" {
+			t.Errorf("GetLatestMessage after SetLatestMessage: got %+v, %v", m, err)
+		}
+		tree, err := engine.Get(threadId, ctx)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		latest := []string{}
+		for _, m := range tree.Messages {
+			if m.Latest {
+				latest = append(latest, m.MessageId)
+			}
+		}
+		expectIds(t, "latest messages", latest, []string{"msg_07"})
+
+		thread, err := engine.Pick(threadId, nil, nil, ctx)
+		if err != nil {
+			t.Fatalf("Pick(root, latest): %v", err)
+		}
+		expectIds(t, "Pick(root, latest)", ids(thread.Messages), []string{"msg_01", "msg_07"})
+
+		_, err = engine.SetLatestMessage(threadId, nil, ctx)
+		expectErr(t, "SetLatestMessage(nil)", err, Impl.ErrInvalidMessage)
+		_, err = engine.SetLatestMessage(threadId, msg("unknown"), ctx)
+		expectErr(t, "SetLatestMessage(unknown)", err, Impl.ErrMessageNotFound)
+		_, err = engine.SetLatestMessage("unknown", msg("msg_07"), ctx)
+		expectErr(t, "SetLatestMessage(unknown thread)", err, Impl.ErrThreadNotFound)
+		// a failed call leaves the latest message alone
+		m, err = engine.GetLatestMessage(threadId, ctx)
+		if err != nil || m.MessageId != "msg_07" {
+			t.Errorf("GetLatestMessage after SetLatestMessage(unknown): got %+v, %v", m, err)
+		}
+	}},
+	{"Pick", func(t *testing.T, engine Impl.TreeEngine, threadId string, ctx context.Context) {
+		// picking from the root does not include the root
+		thread, err := engine.Pick(threadId, nil, nil, ctx)
+		if err != nil {
+			t.Fatalf("Pick(root, latest): %v", err)
+		}
+		expectIds(t, "Pick(root, latest)", ids(thread.Messages), latestPath)
+
+		thread, err = engine.Pick(threadId, nil, msg("msg_27"), ctx)
+		if err != nil {
+			t.Fatalf("Pick(root, msg_27): %v", err)
+		}
+		expectIds(t, "Pick(root, msg_27)", ids(thread.Messages), latestPath)
+
+		// picking from a message includes both ends
+		thread, err = engine.Pick(threadId, msg("msg_06"), msg("msg_21"), ctx)
+		if err != nil {
+			t.Fatalf("Pick(msg_06, msg_21): %v", err)
+		}
+		expectIds(t, "Pick(msg_06, msg_21)", ids(thread.Messages), []string{"msg_06", "msg_16", "msg_17", "msg_20", "msg_21"})
+
+		// the start has to be above the end, a message is not above itself
+		thread, err = engine.Pick(threadId, msg("msg_06"), msg("msg_06"), ctx)
+		if err != nil {
+			t.Fatalf("Pick(msg_06, msg_06): %v", err)
+		}
+		expectIds(t, "Pick(msg_06, msg_06)", ids(thread.Messages), []string{})
+		thread, err = engine.Pick(threadId, msg("msg_27"), nil, ctx)
+		if err != nil {
+			t.Fatalf("Pick(msg_27, latest): %v", err)
+		}
+		expectIds(t, "Pick(msg_27, latest)", ids(thread.Messages), []string{})
+
+		thread, err = engine.Pick(threadId, nil, msg("msg_03"), ctx)
+		if err != nil {
+			t.Fatalf("Pick(root, msg_03): %v", err)
+		}
+		expectIds(t, "Pick(root, msg_03)", ids(thread.Messages), []string{"msg_03"})
+
+		// there is no path between different branches
+		thread, err = engine.Pick(threadId, msg("msg_01"), msg("msg_27"), ctx)
+		if err != nil {
+			t.Fatalf("Pick(msg_01, msg_27): %v", err)
+		}
+		expectIds(t, "Pick(msg_01, msg_27)", ids(thread.Messages), []string{})
+
+		_, err = engine.Pick(threadId, msg("unknown"), msg("msg_27"), ctx)
+		expectErr(t, "Pick(unknown, msg_27)", err, Impl.ErrMessageNotFound)
+		_, err = engine.Pick(threadId, nil, msg("unknown"), ctx)
+		expectErr(t, "Pick(root, unknown)", err, Impl.ErrMessageNotFound)
+		_, err = engine.Pick("unknown", nil, nil, ctx)
+		expectErr(t, "Pick(unknown thread)", err, Impl.ErrThreadNotFound)
+
+		// a chain of 41 messages hanging from the root
+		chain := Impl.ThreadTree{Root: Impl.ThreadRoot{ThreadId: "chain_thread"}}
+		for i := 0; i <= 40; i++ {
+			chain.Messages = append(chain.Messages, *msg(fmt.Sprintf("chain_%02d", i)))
+			r := Impl.Triple{Relation: "CHILD", EndId: fmt.Sprintf("chain_%02d", i)}
+			if i > 0 {
+				r.StartId = fmt.Sprintf("chain_%02d", i-1)
+			}
+			chain.Relations = append(chain.Relations, r)
+		}
+		if err := engine.AddTree("chain_thread", chain, ctx); err != nil {
+			t.Fatalf("AddTree(chain_thread): %v", err)
+		}
+		defer engine.Delete("chain_thread", nil, ctx)
+		_, err = engine.Pick("chain_thread", nil, msg("chain_40"), ctx)
+		expectErr(t, "Pick(root, chain_40)", err, Impl.ErrDepthExceeded)
+		thread, err = engine.Pick("chain_thread", msg("chain_00"), msg("chain_40"), ctx)
+		if err != nil {
+			t.Fatalf("Pick(chain_00, chain_40): %v", err)
+		}
+		if len(thread.Messages) != 41 {
+			t.Errorf("Pick(chain_00, chain_40): got %d messages, want 41", len(thread.Messages))
+		}
+	}},
+	{"AddMessage", func(t *testing.T, engine Impl.TreeEngine, threadId string, ctx context.Context) {
+		if err := engine.AddMessage(threadId, msg("new_00"), nil, ctx); err != nil {
+			t.Fatalf("AddMessage(new_00, root): %v", err)
+		}
+		if err := engine.AddMessage(threadId, msg("new_01"), msg("new_00"), ctx); err != nil {
+			t.Fatalf("AddMessage(new_01, new_00): %v", err)
+		}
+		expectInt(t, "Size", 30)(engine.Size(threadId, ctx))
+		expectInt(t, "Degree(root)", 7)(engine.Degree(threadId, nil, ctx))
+		thread, err := engine.Pick(threadId, nil, msg("new_01"), ctx)
+		if err != nil {
+			t.Fatalf("Pick(root, new_01): %v", err)
+		}
+		expectIds(t, "Pick(root, new_01)", ids(thread.Messages), []string{"new_00", "new_01"})
+
+		err = engine.AddMessage(threadId, nil, nil, ctx)
+		expectErr(t, "AddMessage(nil)", err, Impl.ErrInvalidMessage)
+		err = engine.AddMessage(threadId, msg("new_02"), msg("unknown"), ctx)
+		expectErr(t, "AddMessage(new_02, unknown)", err, Impl.ErrMessageNotFound)
+		var messageErr *Impl.MessageError
+		if !errors.As(err, &messageErr) || messageErr.ThreadId != threadId || messageErr.MessageId != "unknown" {
+			t.Errorf("AddMessage(new_02, unknown): got %v, want a *MessageError for unknown", err)
+		}
+		err = engine.AddMessage(threadId, msg("new_01"), nil, ctx)
+		expectErr(t, "AddMessage(new_01, root)", err, Impl.ErrDuplicateMessage)
+		err = engine.AddMessage("unknown", msg("new_02"), nil, ctx)
+		expectErr(t, "AddMessage(unknown thread)", err, Impl.ErrThreadNotFound)
+		expectInt(t, "Size", 30)(engine.Size(threadId, ctx))
+	}},
+	{"AddTree", func(t *testing.T, engine Impl.TreeEngine, threadId string, ctx context.Context) {
+		demo := Impl.GetDemoTree()
+		root := demo.Root
+		invalid := map[string]Impl.ThreadTree{
+			"thread id mismatch": *demo,
+			"no messages":        {Root: root, Relations: demo.Relations},
+			"no relations":       {Root: root, Messages: demo.Messages},
+			"orphan message": {Root: root, Messages: []Impl.Message{*msg("new_00"), *msg("new_01")},
+				Relations: []Impl.Triple{{Relation: "CHILD", EndId: "new_00"}}},
+			"two parents": {Root: root, Messages: []Impl.Message{*msg("new_00")},
+				Relations: []Impl.Triple{{Relation: "CHILD", EndId: "new_00"}, {StartId: "msg_00", Relation: "CHILD", EndId: "new_00"}}},
+			"unknown start": {Root: root, Messages: []Impl.Message{*msg("new_00")},
+				Relations: []Impl.Triple{{StartId: "unknown", Relation: "CHILD", EndId: "new_00"}}},
+			"new parent": {Root: root, Messages: []Impl.Message{*msg("new_00"), *msg("msg_27")},
+				Relations: []Impl.Triple{{Relation: "CHILD", EndId: "new_00"}, {StartId: "new_00", Relation: "CHILD", EndId: "msg_27"}}},
+		}
+		for name, tree := range invalid {
+			target := threadId
+			if name == "thread id mismatch" {
+				target = "other"
+			}
+			err := engine.AddTree(target, tree, ctx)
+			expectErr(t, "AddTree("+name+")", err, Impl.ErrInvalidTree)
+		}
+		expectInt(t, "Size", 28)(engine.Size(threadId, ctx))
+
+		// trees merge into the stored thread and can hang below stored messages
+		tree := Impl.ThreadTree{
+			Root:      root,
+			Messages:  []Impl.Message{*msg("new_00")},
+			Relations: []Impl.Triple{{StartId: "msg_27", Relation: "CHILD", EndId: "new_00"}},
+		}
+		if err := engine.AddTree(threadId, tree, ctx); err != nil {
+			t.Fatalf("AddTree(new_00): %v", err)
+		}
+		expectInt(t, "Size", 29)(engine.Size(threadId, ctx))
+		expectInt(t, "Degree(msg_27)", 1)(engine.Degree(threadId, msg("msg_27"), ctx))
+	}},
+	{"Payload", func(t *testing.T, engine Impl.TreeEngine, threadId string, ctx context.Context) {
+		added := richMessage("new_00", "user")
+		added.Rating = &Impl.Rating{Thumbs: 1, Score: 0.75}
+		if err := engine.AddMessage(threadId, &added, msg("msg_27"), ctx); err != nil {
+			t.Fatalf("AddMessage(new_00): %v", err)
+		}
+		thread, err := engine.Pick(threadId, msg("msg_26"), msg("new_00"), ctx)
+		if err != nil {
+			t.Fatalf("Pick(msg_26, new_00): %v", err)
+		}
+		expectIds(t, "Pick(msg_26, new_00)", ids(thread.Messages), []string{"msg_26", "msg_27", "new_00"})
+		if len(thread.Messages) == 3 {
+			expectMessage(t, "Pick(msg_26, new_00)", thread.Messages[2], added)
+		}
+
+		// a second thread where every message carries a payload
+		first, second := richMessage("rich_00", "user"), richMessage("rich_01", "assistant")
+		second.Latest = true
+		second.Metadata = map[string]any{"model": "gpt-4o", "tokens": 12.0, "tags": []any{"a", "b"}}
+		second.Rating = &Impl.Rating{Thumbs: -1}
+		tree := Impl.ThreadTree{
+			Root:     Impl.ThreadRoot{ThreadId: "rich_thread"},
+			Messages: []Impl.Message{first, second},
+			Relations: []Impl.Triple{
+				{Relation: "CHILD", EndId: "rich_00"},
+				{StartId: "rich_00", Relation: "CHILD", EndId: "rich_01"},
+			},
+		}
+		if err := engine.AddTree("rich_thread", tree, ctx); err != nil {
+			t.Fatalf("AddTree(rich_thread): %v", err)
+		}
+		defer engine.Delete("rich_thread", nil, ctx)
+
+		got, err := engine.Get("rich_thread", ctx)
+		if err != nil {
+			t.Fatalf("Get(rich_thread): %v", err)
+		}
+		expectIds(t, "Get(rich_thread)", ids(got.Messages), []string{"rich_00", "rich_01"})
+		if len(got.Messages) == 2 {
+			expectMessage(t, "Get(rich_thread)", got.Messages[0], first)
+			expectMessage(t, "Get(rich_thread)", got.Messages[1], second)
+		}
+		got, err = engine.GetChildren("rich_thread", msg("rich_00"), 1, ctx)
+		if err != nil {
+			t.Fatalf("GetChildren(rich_00, 1): %v", err)
+		}
+		expectIds(t, "GetChildren(rich_00, 1)", ids(got.Messages), []string{"rich_00", "rich_01"})
+		if len(got.Messages) == 2 {
+			expectMessage(t, "GetChildren(rich_00, 1)", got.Messages[1], second)
+		}
+		latest, err := engine.GetLatestMessage("rich_thread", ctx)
+		if err != nil {
+			t.Fatalf("GetLatestMessage(rich_thread): %v", err)
+		}
+		expectMessage(t, "GetLatestMessage(rich_thread)", latest, second)
+	}},
+	{"Isolation", func(t *testing.T, engine Impl.TreeEngine, threadId string, ctx context.Context) {
+		// a second copy of the demo tree shares every message id with the first one
+		other := Impl.GetDemoTree()
+		other.Root.ThreadId = "tree_0001"
+		if err := engine.AddTree("tree_0001", *other, ctx); err != nil {
+			t.Fatalf("AddTree(tree_0001): %v", err)
+		}
+		defer engine.Delete("tree_0001", nil, ctx)
+
+		if err := engine.AddMessage("tree_0001", msg("new_00"), msg("msg_13"), ctx); err != nil {
+			t.Fatalf("AddMessage(tree_0001, new_00): %v", err)
+		}
+		expectInt(t, "Degree(msg_13)", 0)(engine.Degree(threadId, msg("msg_13"), ctx))
+		expectInt(t, "Degree(tree_0001, msg_13)", 1)(engine.Degree("tree_0001", msg("msg_13"), ctx))
+		if err := engine.AddMessage(threadId, msg("new_00"), msg("msg_00"), ctx); err != nil {
+			t.Errorf("AddMessage(new_00): the same id in another thread should be allowed: %v", err)
+		}
+
+		if _, err := engine.SetLatestMessage("tree_0001", msg("msg_07"), ctx); err != nil {
+			t.Fatalf("SetLatestMessage(tree_0001, msg_07): %v", err)
+		}
+		m, err := engine.GetLatestMessage(threadId, ctx)
+		if err != nil || m.MessageId != "msg_27" {
+			t.Errorf("GetLatestMessage: got %+v, %v, want msg_27", m, err)
+		}
+		thread, err := engine.Pick(threadId, nil, nil, ctx)
+		if err != nil {
+			t.Fatalf("Pick(root, latest): %v", err)
+		}
+		expectIds(t, "Pick(root, latest)", ids(thread.Messages), latestPath)
+
+		if err := engine.Delete("tree_0001", msg("msg_06"), ctx); err != nil {
+			t.Fatalf("Delete(tree_0001, msg_06): %v", err)
+		}
+		expectInt(t, "Size", 29)(engine.Size(threadId, ctx))
+		expectInt(t, "Size(tree_0001)", 14)(engine.Size("tree_0001", ctx))
+		expectInt(t, "Degree(msg_06)", 2)(engine.Degree(threadId, msg("msg_06"), ctx))
+	}},
+	{"Delete", func(t *testing.T, engine Impl.TreeEngine, threadId string, ctx context.Context) {
+		// removes msg_06 and the 14 messages below it
+		if err := engine.Delete(threadId, msg("msg_06"), ctx); err != nil {
+			t.Fatalf("Delete(msg_06): %v", err)
+		}
+		expectInt(t, "Size", 13)(engine.Size(threadId, ctx))
+		expectInt(t, "Degree(msg_00)", 0)(engine.Degree(threadId, msg("msg_00"), ctx))
+		expectInt(t, "Depth", 4)(engine.Depth(threadId, ctx))
+
+		err := engine.Delete(threadId, msg("msg_06"), ctx)
+		expectErr(t, "Delete(msg_06) again", err, Impl.ErrMessageNotFound)
+
+		if err := engine.Delete(threadId, nil, ctx); err != nil {
+			t.Fatalf("Delete(root): %v", err)
+		}
+		_, err = engine.Size(threadId, ctx)
+		expectErr(t, "Size after Delete(root)", err, Impl.ErrThreadNotFound)
+		_, err = engine.Get(threadId, ctx)
+		expectErr(t, "Get after Delete(root)", err, Impl.ErrThreadNotFound)
+		err = engine.Delete(threadId, nil, ctx)
+		expectErr(t, "Delete(root) again", err, Impl.ErrThreadNotFound)
+	}},
+	{"ListThreads", func(t *testing.T, engine Impl.TreeEngine, threadId string, ctx context.Context) {
+		if _, ok := engine.(Impl.Lister); !ok {
+			t.Skip("the engine does not implement Lister")
+		}
+		// far in the future so it comes first by activity even in a database with other threads
+		first := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+		last := time.Date(2100, 1, 2, 3, 0, 0, 0, time.FixedZone("", 5*3600))
+		listed := Impl.ThreadTree{
+			Root: Impl.ThreadRoot{ThreadId: "tree_list"},
+			Messages: []Impl.Message{
+				{MessageId: "a", CreatedAt: first, Metadata: map[string]any{
+					"list":   "enginetest",
+					"tags":   []any{"x", "y"},
+					"source": map[string]any{"app": "enginetest", "v": 2},
+				}},
+				{MessageId: "b", CreatedAt: last, Latest: true, Metadata: map[string]any{"list": "enginetest", "n": 2, "ok": true}},
+			},
+			Relations: []Impl.Triple{{Relation: "CHILD", EndId: "a"}, {StartId: "a", Relation: "CHILD", EndId: "b"}},
+		}
+		if err := engine.AddTree("tree_list", listed, ctx); err != nil {
+			t.Fatalf("AddTree(tree_list): %v", err)
+		}
+		t.Cleanup(func() { engine.Delete("tree_list", nil, context.Background()) })
+		list := func(opts Impl.ListOptions) Impl.ThreadPage {
+			t.Helper()
+			page, err := Impl.ListThreads(engine, opts, ctx)
+			if err != nil {
+				t.Fatalf("ListThreads(%+v): %v", opts, err)
+			}
+			return page
+		}
+
+		// one thread per page, in the order of the ids
+		seen := []string{}
+		opts := Impl.ListOptions{Limit: 1}
+		for {
+			page := list(opts)
+			if len(page.Threads) > 1 {
+				t.Fatalf("ListThreads: got %d threads with a limit of 1", len(page.Threads))
+			}
+			for _, s := range page.Threads {
+				seen = append(seen, s.ThreadId)
+				if s.ThreadId == threadId && (s.Size != 28 || s.Depth != 8 || s.Latest == nil || s.Latest.MessageId != "msg_27") {
+					t.Errorf("ListThreads: got %+v for %s", s, threadId)
+				}
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			opts.Cursor = page.NextCursor
+		}
+		for i := 1; i < len(seen); i++ {
+			if seen[i-1] >= seen[i] {
+				t.Errorf("ListThreads: got %s after %s", seen[i], seen[i-1])
+			}
+		}
+		if !slices.Contains(seen, threadId) || !slices.Contains(seen, "tree_list") {
+			t.Errorf("ListThreads: got %v, want %s and tree_list in it", seen, threadId)
+		}
+
+		page := list(Impl.ListOptions{OrderBy: Impl.ListByActivity, Limit: 1})
+		if len(page.Threads) != 1 || page.Threads[0].ThreadId != "tree_list" {
+			t.Fatalf("ListThreads by activity: got %+v, want tree_list first", page.Threads)
+		}
+		s := page.Threads[0]
+		if s.Size != 2 || s.Depth != 2 || !s.CreatedAt.Equal(first) || !s.UpdatedAt.Equal(last) || s.Latest == nil || s.Latest.MessageId != "b" {
+			t.Errorf("ListThreads by activity: got %+v", s)
+		}
+		page = list(Impl.ListOptions{OrderBy: Impl.ListByActivity, Limit: 1, Cursor: page.NextCursor})
+		if len(page.Threads) != 1 || page.Threads[0].ThreadId == "tree_list" {
+			t.Errorf("ListThreads by activity: got %+v on the second page", page.Threads)
+		}
+
+		page = list(Impl.ListOptions{Metadata: map[string]any{"list": "enginetest", "n": 2}})
+		if len(page.Threads) != 1 || page.Threads[0].ThreadId != "tree_list" || page.NextCursor != "" {
+			t.Errorf("ListThreads with metadata: got %+v", page)
+		}
+		page = list(Impl.ListOptions{Metadata: map[string]any{
+			"tags":   []any{"x", "y"},
+			"source": map[string]any{"app": "enginetest", "v": 2},
+		}})
+		if len(page.Threads) != 1 || page.Threads[0].ThreadId != "tree_list" {
+			t.Errorf("ListThreads with array and object metadata: got %+v", page.Threads)
+		}
+		// every key has to be equal, a value that is only part of the stored one or of another JSON type is not
+		for _, metadata := range []map[string]any{
+			{"list": "enginetest", "n": 3},
+			{"tags": []any{"x"}},
+			{"source": map[string]any{"app": "enginetest"}},
+			{"ok": 1},
+		} {
+			page = list(Impl.ListOptions{Metadata: metadata})
+			if len(page.Threads) != 0 {
+				t.Errorf("ListThreads with metadata %v: got %+v", metadata, page.Threads)
+			}
+		}
+
+		// the activity follows added and deleted messages
+		later := last.Add(time.Hour)
+		if err := engine.AddMessage("tree_list", &Impl.Message{MessageId: "c", CreatedAt: later}, msg("b"), ctx); err != nil {
+			t.Fatalf("AddMessage(tree_list, c): %v", err)
+		}
+		page = list(Impl.ListOptions{OrderBy: Impl.ListByActivity, Limit: 1})
+		if len(page.Threads) != 1 || page.Threads[0].ThreadId != "tree_list" || !page.Threads[0].UpdatedAt.Equal(later) {
+			t.Errorf("ListThreads by activity after AddMessage: got %+v", page.Threads)
+		}
+		if err := engine.Delete("tree_list", msg("b"), ctx); err != nil {
+			t.Fatalf("Delete(tree_list, b): %v", err)
+		}
+		page = list(Impl.ListOptions{OrderBy: Impl.ListByActivity, Limit: 1})
+		if len(page.Threads) != 1 || page.Threads[0].ThreadId != "tree_list" || !page.Threads[0].UpdatedAt.Equal(first) {
+			t.Errorf("ListThreads by activity after Delete: got %+v", page.Threads)
+		}
+
+		// merged trees can each bring a latest message, the thread is still listed once with one of them
+		for _, id := range []string{"d", "e"} {
+			merged := Impl.ThreadTree{
+				Root:      listed.Root,
+				Messages:  []Impl.Message{{MessageId: id, Latest: true}},
+				Relations: []Impl.Triple{{StartId: "a", Relation: "CHILD", EndId: id}},
+			}
+			if err := engine.AddTree("tree_list", merged, ctx); err != nil {
+				t.Fatalf("AddTree(tree_list, %s): %v", id, err)
+			}
+		}
+		for _, orderBy := range []string{Impl.ListByThreadId, Impl.ListByActivity} {
+			found := []Impl.ThreadSummary{}
+			opts := Impl.ListOptions{OrderBy: orderBy, Limit: 100}
+			for {
+				page := list(opts)
+				for _, s := range page.Threads {
+					if s.ThreadId == "tree_list" {
+						found = append(found, s)
+					}
+				}
+				if page.NextCursor == "" {
+					break
+				}
+				opts.Cursor = page.NextCursor
+			}
+			if len(found) != 1 || found[0].Size != 3 || found[0].Latest == nil || !slices.Contains([]string{"d", "e"}, found[0].Latest.MessageId) {
+				t.Errorf("ListThreads by %s after merging latest messages: got %+v", orderBy, found)
+			}
+		}
+
+		_, err := Impl.ListThreads(engine, Impl.ListOptions{OrderBy: Impl.ListByActivity, Cursor: opts.Cursor}, ctx)
+		expectErr(t, "ListThreads with a cursor of another order", err, Impl.ErrInvalidListOptions)
+		_, err = Impl.ListThreads(engine, Impl.ListOptions{Limit: -1}, ctx)
+		expectErr(t, "ListThreads(limit -1)", err, Impl.ErrInvalidListOptions)
+	}},
+}
+
+func msg(id string) *Impl.Message {
+	return &Impl.Message{MessageId: id}
+}
+
+func richMessage(id, role string) Impl.Message {
+	return Impl.Message{
+		MessageId: id,
+		Role:      role,
+		Content:   "content of " + id,
+		Author:    "author of " + id,
+		CreatedAt: time.Date(2024, 3, 1, 12, 30, 15, 0, time.UTC),
+		Metadata:  map[string]any{"source": "enginetest", "nested": map[string]any{"id": id}},
+	}
+}
+
+func ids(messages []Impl.Message) []string {
+	out := []string{}
+	for _, m := range messages {
+		out = append(out, m.MessageId)
+	}
+	return out
+}
+
+func sorted(s []string) []string {
+	out := slices.Clone(s)
+	sort.Strings(out)
+	return out
+}
+
+func expectInt(t *testing.T, name string, want int) func(int, error) {
+	t.Helper()
+	return func(got int, err error) {
+		t.Helper()
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if got != want {
+			t.Errorf("%s: got %d, want %d", name, got, want)
+		}
+	}
+}
+
+func expectErr(t *testing.T, name string, err, target error) {
+	t.Helper()
+	if !errors.Is(err, target) {
+		t.Errorf("%s: got error %v, want %v", name, err, target)
+	}
+}
+
+func expectIds(t *testing.T, name string, got, want []string) {
+	t.Helper()
+	if !slices.Equal(got, want) {
+		t.Errorf("%s: got %v, want %v", name, got, want)
+	}
+}
+
+func expectRelations(t *testing.T, name string, got, want []Impl.Triple) {
+	t.Helper()
+	key := func(r Impl.Triple) string { return r.StartId + "-" + r.Relation + "->" + r.EndId }
+	g, w := []string{}, []string{}
+	for _, r := range got {
+		g = append(g, key(r))
+	}
+	for _, r := range want {
+		w = append(w, key(r))
+	}
+	expectIds(t, name, sorted(g), sorted(w))
+}
+
+func expectMessage(t *testing.T, name string, got, want Impl.Message) {
+	t.Helper()
+	if got.MessageId != want.MessageId || got.Latest != want.Latest || got.Role != want.Role ||
+		got.Content != want.Content || got.Author != want.Author || !got.CreatedAt.Equal(want.CreatedAt) ||
+		!reflect.DeepEqual(got.Metadata, want.Metadata) || !reflect.DeepEqual(got.Rating, want.Rating) {
+		t.Errorf("%s: got %+v, want %+v", name, got, want)
+	}
+}
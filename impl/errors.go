@@ -0,0 +1,186 @@
+package impl
+
+import (
+	"errors"
+	"fmt"
+)
+
+/*
+Errors returned by every TreeEngine backend. They are wrapped with more context so check them with `errors.Is`, and
+use `errors.As` with a *MessageError to get the ids involved. Anything else is a failure of the underlying store.
+
+	_, err := backend.Pick(threadId, nil, &Impl.Message{MessageId: "msg_27"}, ctx)
+	if errors.Is(err, Impl.ErrMessageNotFound) {
+		...
+	}
+*/
+var (
+	ErrThreadNotFound   = errors.New("thread not found")
+	ErrMessageNotFound  = errors.New("message not found")
+	ErrDuplicateMessage = errors.New("message already exists")
+	ErrInvalidMessage   = errors.New("invalid message")
+	ErrInvalidTree      = errors.New("invalid tree")
+	ErrDepthExceeded    = errors.New("depth out of range")
+	ErrNoLatest         = errors.New("no latest message")
+	ErrNotConnected     = errors.New("backend is not connected")
+
+	ErrInvalidListOptions = errors.New("invalid list options")
+)
+
+// MessageError is returned when a particular message is missing or clashes with a stored one, it unwraps to
+// ErrMessageNotFound or ErrDuplicateMessage.
+type MessageError struct {
+	ThreadId  string
+	MessageId string
+	Err       error
+}
+
+func (e *MessageError) Error() string {
+	return fmt.Sprintf("%s: %s in thread %s", e.Err, e.MessageId, e.ThreadId)
+}
+
+func (e *MessageError) Unwrap() error {
+	return e.Err
+}
+
+func threadNotFound(threadId string) error {
+	return fmt.Errorf("%w: %s", ErrThreadNotFound, threadId)
+}
+
+func messageNotFound(threadId, messageId string) error {
+	return &MessageError{ThreadId: threadId, MessageId: messageId, Err: ErrMessageNotFound}
+}
+
+func duplicateMessage(threadId, messageId string) error {
+	return &MessageError{ThreadId: threadId, MessageId: messageId, Err: ErrDuplicateMessage}
+}
+
+func invalidTree(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidTree, fmt.Sprintf(format, args...))
+}
+
+// validateDepth checks the `depth` given to GetChildren
+func validateDepth(depth int) error {
+	if depth <= 0 {
+		return fmt.Errorf("%w: depth cannot be less than 1", ErrDepthExceeded)
+	} else if depth > 10 {
+		return fmt.Errorf("%w: depth cannot be more than 10", ErrDepthExceeded)
+	}
+	return nil
+}
+
+func pickTooLong() error {
+	return fmt.Errorf("%w: Pick walks at most %d relations", ErrDepthExceeded, maxPickLength)
+}
+
+// validateTree runs the checks on AddTree input that do not need the stored thread. Relations can start from messages
+// that are already stored, but every message in the tree has to hang below the root or one of those.
+func validateTree(threadId string, tree ThreadTree) error {
+	if tree.Root.ThreadId != threadId {
+		return invalidTree("threadId mismatch")
+	} else if len(tree.Messages) == 0 {
+		return invalidTree("no messages in the tree")
+	} else if len(tree.Relations) == 0 {
+		return invalidTree("no relations in the tree")
+	}
+	inTree := map[string]bool{}
+	for _, m := range tree.Messages {
+		if m.MessageId == "" {
+			return invalidTree("message id cannot be empty")
+		} else if inTree[m.MessageId] {
+			return invalidTree("message %s is listed twice", m.MessageId)
+		}
+		inTree[m.MessageId] = true
+	}
+	children := map[string][]string{}
+	hasParent := map[string]bool{}
+	for _, r := range tree.Relations {
+		if hasParent[r.EndId] {
+			return invalidTree("message %s has more than one parent", r.EndId)
+		}
+		hasParent[r.EndId] = true
+		children[r.StartId] = append(children[r.StartId], r.EndId)
+	}
+
+	frontier := []string{""}
+	for startId := range children {
+		if startId != "" && !inTree[startId] {
+			frontier = append(frontier, startId)
+		}
+	}
+	reached := map[string]bool{}
+	for len(frontier) > 0 {
+		next := []string{}
+		for _, p := range frontier {
+			for _, c := range children[p] {
+				if !reached[c] {
+					reached[c] = true
+					next = append(next, c)
+				}
+			}
+		}
+		frontier = next
+	}
+	for _, m := range tree.Messages {
+		if !reached[m.MessageId] {
+			return invalidTree("message %s is not connected to the tree", m.MessageId)
+		}
+	}
+	return nil
+}
+
+// errorCodes name the errors above for servers, clients turn the names back into the same errors with ErrorFromCode
+var errorCodes = []struct {
+	code string
+	err  error
+}{
+	{"thread_not_found", ErrThreadNotFound},
+	{"message_not_found", ErrMessageNotFound},
+	{"duplicate_message", ErrDuplicateMessage},
+	{"invalid_message", ErrInvalidMessage},
+	{"invalid_tree", ErrInvalidTree},
+	{"depth_exceeded", ErrDepthExceeded},
+	{"no_latest", ErrNoLatest},
+	{"not_connected", ErrNotConnected},
+	{"invalid_list_options", ErrInvalidListOptions},
+	{"unsupported", errors.ErrUnsupported},
+}
+
+// ErrorCode is the name of the error `err` wraps, it is empty for errors that are not from this package
+func ErrorCode(err error) string {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return ""
+}
+
+// codeError is an error rebuilt by ErrorFromCode, it keeps the text the server sent
+type codeError struct {
+	text string
+	err  error
+}
+
+func (e *codeError) Error() string {
+	return e.text
+}
+
+func (e *codeError) Unwrap() error {
+	return e.err
+}
+
+// ErrorFromCode rebuilds an error sent by a server as its code and text, missing and duplicate messages come back as a
+// *MessageError. Unknown codes give an error with just the text.
+func ErrorFromCode(code, text, threadId, messageId string) error {
+	for _, c := range errorCodes {
+		if c.code != code {
+			continue
+		}
+		if messageId != "" && (c.err == ErrMessageNotFound || c.err == ErrDuplicateMessage) {
+			return &MessageError{ThreadId: threadId, MessageId: messageId, Err: c.err}
+		}
+		return &codeError{text: text, err: c.err}
+	}
+	return errors.New(text)
+}
@@ -2,14 +2,17 @@ package impl
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"time"
 )
 
 /*
 Here are the data structures that are used for storage and API calls. A speed run through them:
 
 - ThreadRoot: This is a special node that contains the thread_id and is the root of the tree.
-- Message: This is a node that contains the message_id and some attributes like is it the latest message.
+- Message: This is a node that contains the message_id, the payload (role, content, author, created_at, an optional
+  rating and free form metadata) and some attributes like is it the latest message.
 - Thread: Thread is a list of messages
 - Triple: This is a relation between two nodes, it is a directed edge from startId to endId with a relation.
 - ThreadTree: This is the entire tree, it contains the thread_id, messages and relations.
@@ -22,10 +25,37 @@ type ThreadRoot struct {
 }
 
 type Message struct {
-	MessageId string `json:"id"`
-	Latest    bool   `json:"latest"`
+	MessageId string         `json:"id"`
+	Latest    bool           `json:"latest"`
+	Role      string         `json:"role,omitempty"`
+	Content   string         `json:"content,omitempty"`
+	CreatedAt time.Time      `json:"created_at,omitempty"` // left out when zero, see MarshalJSON
+	Author    string         `json:"author,omitempty"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+	Rating    *Rating        `json:"rating,omitempty"`
 }
 
+// MarshalJSON leaves out a CreatedAt that was never set, omitempty does not apply to a time.Time
+func (m Message) MarshalJSON() ([]byte, error) {
+	type message Message
+	var createdAt *time.Time
+	if !m.CreatedAt.IsZero() {
+		createdAt = &m.CreatedAt
+	}
+	return json.Marshal(struct {
+		message
+		CreatedAt *time.Time `json:"created_at,omitempty"`
+	}{message(m), createdAt})
+}
+
+// Rating is the feedback given on a message, nil when it was never rated
+type Rating struct {
+	Thumbs int     `json:"thumbs"` // +1 for up, -1 for down, 0 when only scored
+	Score  float64 `json:"score"`
+}
+
+// MessageFromDict reads a message from the properties of a stored node, `metadata` can either be a map or the JSON
+// string written by ToDict and `created_at` can either be a time or an RFC 3339 string.
 func MessageFromDict(dict map[string]interface{}) Message {
 	m := Message{}
 	if id := dict["id"]; id != nil {
@@ -34,9 +64,108 @@ func MessageFromDict(dict map[string]interface{}) Message {
 	if latestMessage := dict["latest"]; latestMessage != nil {
 		m.Latest = latestMessage.(bool)
 	}
+	if role, ok := dict["role"].(string); ok {
+		m.Role = role
+	}
+	if content, ok := dict["content"].(string); ok {
+		m.Content = content
+	}
+	if author, ok := dict["author"].(string); ok {
+		m.Author = author
+	}
+	switch createdAt := dict["created_at"].(type) {
+	case time.Time:
+		m.CreatedAt = createdAt
+	case string:
+		m.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+	}
+	switch metadata := dict["metadata"].(type) {
+	case map[string]any:
+		m.Metadata = metadata
+	case string:
+		m.Metadata, _ = decodeMetadata(metadata)
+	}
+	thumbs, hasThumbs := number(dict["thumbs"])
+	score, hasScore := number(dict["score"])
+	if hasThumbs || hasScore {
+		m.Rating = &Rating{Thumbs: int(thumbs), Score: score}
+	}
+	return m
+}
+
+// number reads a stored number, drivers hand them back as int64 or float64
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// ToDict returns the properties to store for the message, empty fields are left out, the metadata is encoded as a
+// JSON string and the rating is split into `thumbs` and `score` since most stores only take flat values.
+func (m Message) ToDict() (map[string]interface{}, error) {
+	dict := map[string]interface{}{"id": m.MessageId, "latest": m.Latest}
+	if m.Role != "" {
+		dict["role"] = m.Role
+	}
+	if m.Content != "" {
+		dict["content"] = m.Content
+	}
+	if m.Author != "" {
+		dict["author"] = m.Author
+	}
+	if !m.CreatedAt.IsZero() {
+		dict["created_at"] = m.CreatedAt
+	}
+	if len(m.Metadata) > 0 {
+		metadata, err := encodeMetadata(m.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		dict["metadata"] = metadata
+	}
+	if m.Rating != nil {
+		dict["thumbs"] = int64(m.Rating.Thumbs)
+		dict["score"] = m.Rating.Score
+	}
+	return dict, nil
+}
+
+// clone returns a copy of the message that does not share the metadata map or the rating
+func (m Message) clone() Message {
+	if m.Rating != nil {
+		rating := *m.Rating
+		m.Rating = &rating
+	}
+	if m.Metadata != nil {
+		metadata := make(map[string]any, len(m.Metadata))
+		for k, v := range m.Metadata {
+			metadata[k] = v
+		}
+		m.Metadata = metadata
+	}
 	return m
 }
 
+func encodeMetadata(metadata map[string]any) (string, error) {
+	data, err := json.Marshal(metadata)
+	return string(data), err
+}
+
+func decodeMetadata(data string) (map[string]any, error) {
+	if data == "" {
+		return nil, nil
+	}
+	metadata := map[string]any{}
+	err := json.Unmarshal([]byte(data), &metadata)
+	return metadata, err
+}
+
 type Thread struct {
 	Messages []Message `json:"messages"`
 }
@@ -54,7 +183,7 @@ type ThreadTree struct {
 }
 
 /*
-Here's a simple thread we are going to use to load the tree
+Here's a simple thread we are going to use to load the tree, as drawn by RenderTree
 
 // <ThreadRoot: tree_0000>
 // ├── [msg_00]
@@ -0,0 +1,38 @@
+package impl_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	Impl "github.com/yashbonde/vriksham/impl"
+)
+
+// a message without a timestamp has no created_at in its JSON, one with a timestamp keeps it
+func TestMessageJSON(t *testing.T) {
+	data, err := json.Marshal(Impl.Message{MessageId: "msg_00", Role: "user"})
+	if err != nil || strings.Contains(string(data), "created_at") {
+		t.Errorf("Marshal: got %s, %v, want no created_at", data, err)
+	}
+
+	want := Impl.Message{
+		MessageId: "msg_01",
+		Latest:    true,
+		CreatedAt: time.Date(2024, 3, 1, 12, 30, 15, 0, time.UTC),
+		Metadata:  map[string]any{"model": "demo"},
+		Rating:    &Impl.Rating{Thumbs: 1},
+	}
+	data, err = json.Marshal([]Impl.Message{want})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	got := []Impl.Message{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal(%s): %v", data, err)
+	}
+	if len(got) != 1 || got[0].MessageId != want.MessageId || !got[0].Latest || !got[0].CreatedAt.Equal(want.CreatedAt) ||
+		got[0].Metadata["model"] != "demo" || got[0].Rating == nil || got[0].Rating.Thumbs != 1 {
+		t.Errorf("Unmarshal(%s): got %+v, want %+v", data, got, want)
+	}
+}
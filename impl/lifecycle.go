@@ -0,0 +1,120 @@
+package impl
+
+import (
+	"context"
+	"runtime/debug"
+)
+
+/*
+Lifecycle is implemented by every backend next to TreeEngine, it is kept out of TreeEngine so an engine that holds no
+connection can still be used as one. Use the Close, Ping and Info functions below on a TreeEngine, they do the type
+assertions and treat an engine without the methods as always up.
+
+	backend := &Impl.Backend_SQLite{Path: "threads.db"}
+	if err := backend.Connect(ctx); err != nil {
+		...
+	}
+	defer backend.Close()
+*/
+type Lifecycle interface {
+	// Connect opens the connection, or the file, and prepares the schema
+	Connect(ctx context.Context) error
+
+	// Close releases the connection, the engine cannot be used afterwards
+	Close() error
+
+	// Ping checks that the store can be reached, it returns ErrNotConnected before Connect and after Close
+	Ping(ctx context.Context) error
+
+	// Info describes the store behind the engine
+	Info(ctx context.Context) (BackendInfo, error)
+}
+
+/*
+Migrator is implemented by the backends with a versioned schema: neo4j, postgres and sqlite. Their Connect already
+migrates, unless Neo4jConfig.SkipMigrations is set, so Migrate is for running the migrations as a step of its own.
+*/
+type Migrator interface {
+	// Migrate applies the migrations the database is missing and returns the schema version before and after
+	Migrate(ctx context.Context) (from, to int, err error)
+}
+
+// BackendInfo says what is behind a TreeEngine
+type BackendInfo struct {
+	Name         string   `json:"name"`              // neo4j, postgres, sqlite, bolt, memory, http or grpc
+	Version      string   `json:"version,omitempty"` // of the database server or library when it is known
+	Capabilities []string `json:"capabilities"`
+
+	// Remote is the engine the server uses, for the clients of the http and grpc servers
+	Remote *BackendInfo `json:"remote,omitempty"`
+}
+
+// Capabilities listed in BackendInfo
+const (
+	// CapabilityPersistent is set when threads outlive the process
+	CapabilityPersistent = "persistent"
+
+	// CapabilityShared is set when several processes can use the same threads at once
+	CapabilityShared = "shared"
+
+	// CapabilityRemote is set when every call goes over the network to a vriksham server
+	CapabilityRemote = "remote"
+)
+
+// Has tells if the backend has the capability
+func (info BackendInfo) Has(capability string) bool {
+	for _, c := range info.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoteInfo is the BackendInfo of a client for a server that uses `remote`, the threads are as persistent as the
+// engine of the server keeps them and always shared between the clients
+func RemoteInfo(name string, remote BackendInfo) BackendInfo {
+	info := BackendInfo{Name: name, Capabilities: []string{CapabilityRemote, CapabilityShared}, Remote: &remote}
+	if remote.Has(CapabilityPersistent) {
+		info.Capabilities = append(info.Capabilities, CapabilityPersistent)
+	}
+	return info
+}
+
+// Close closes `engine` when it implements Lifecycle
+func Close(engine TreeEngine) error {
+	if l, ok := engine.(Lifecycle); ok {
+		return l.Close()
+	}
+	return nil
+}
+
+// Ping pings `engine` when it implements Lifecycle
+func Ping(engine TreeEngine, ctx context.Context) error {
+	if l, ok := engine.(Lifecycle); ok {
+		return l.Ping(ctx)
+	}
+	return nil
+}
+
+// Info returns what `engine` says about itself, an engine that does not implement Lifecycle gets an empty name
+func Info(engine TreeEngine, ctx context.Context) (BackendInfo, error) {
+	if l, ok := engine.(Lifecycle); ok {
+		return l.Info(ctx)
+	}
+	return BackendInfo{Capabilities: []string{}}, nil
+}
+
+// moduleVersion is the version of a go module linked into the binary, empty when it is not known
+func moduleVersion(path string) string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == path {
+			return dep.Version
+		}
+	}
+	return ""
+}
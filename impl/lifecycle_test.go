@@ -0,0 +1,121 @@
+package impl_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	Impl "github.com/yashbonde/vriksham/impl"
+)
+
+// the backends that need a server are only checked to compile
+var (
+	_ Impl.Lifecycle = (*Impl.Backend_Neo4j)(nil)
+	_ Impl.Lifecycle = (*Impl.Backend_Postgres)(nil)
+	_ Impl.Migrator  = (*Impl.Backend_Neo4j)(nil)
+	_ Impl.Migrator  = (*Impl.Backend_Postgres)(nil)
+)
+
+func TestLifecycle(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		backend      Impl.Lifecycle
+		name         string
+		capabilities []string
+	}{
+		{&Impl.Backend_Memory{}, "memory", nil},
+		{&Impl.Backend_SQLite{Path: filepath.Join(dir, "vriksham.db")}, "sqlite", []string{Impl.CapabilityPersistent, Impl.CapabilityShared}},
+		{&Impl.Backend_SQLite{Path: ":memory:"}, "sqlite", nil},
+		{&Impl.Backend_Bolt{Path: filepath.Join(dir, "vriksham.bolt")}, "bolt", []string{Impl.CapabilityPersistent}},
+	} {
+		if err := tc.backend.Connect(ctx); err != nil {
+			t.Fatalf("%s: Connect: %v", tc.name, err)
+		}
+		if err := tc.backend.Ping(ctx); err != nil {
+			t.Errorf("%s: Ping: %v", tc.name, err)
+		}
+		info, err := tc.backend.Info(ctx)
+		if err != nil || info.Name != tc.name || len(info.Capabilities) != len(tc.capabilities) {
+			t.Errorf("%s: Info: got %+v, %v", tc.name, info, err)
+		}
+		for _, c := range tc.capabilities {
+			if !info.Has(c) {
+				t.Errorf("%s: Info: got %v, want %s", tc.name, info.Capabilities, c)
+			}
+		}
+		if tc.name == "sqlite" && info.Version == "" {
+			t.Errorf("sqlite: Info: no version")
+		}
+
+		if err := tc.backend.Close(); err != nil {
+			t.Errorf("%s: Close: %v", tc.name, err)
+		}
+		if err := tc.backend.Close(); err != nil {
+			t.Errorf("%s: Close again: %v", tc.name, err)
+		}
+		if err := tc.backend.Ping(ctx); !errors.Is(err, Impl.ErrNotConnected) {
+			t.Errorf("%s: Ping after Close: got %v, want ErrNotConnected", tc.name, err)
+		}
+		if _, err := tc.backend.Info(ctx); !errors.Is(err, Impl.ErrNotConnected) {
+			t.Errorf("%s: Info after Close: got %v, want ErrNotConnected", tc.name, err)
+		}
+		engine, tree := tc.backend.(Impl.TreeEngine), *Impl.GetDemoTree()
+		if _, err := engine.Size(tree.Root.ThreadId, ctx); !errors.Is(err, Impl.ErrNotConnected) {
+			t.Errorf("%s: Size after Close: got %v, want ErrNotConnected", tc.name, err)
+		}
+		if err := engine.AddTree(tree.Root.ThreadId, tree, ctx); !errors.Is(err, Impl.ErrNotConnected) {
+			t.Errorf("%s: AddTree after Close: got %v, want ErrNotConnected", tc.name, err)
+		}
+	}
+}
+
+// the backends that need a server cannot be used before Connect either
+func TestNotConnected(t *testing.T) {
+	ctx := context.Background()
+	tree := *Impl.GetDemoTree()
+	for name, engine := range map[string]Impl.TreeEngine{
+		"neo4j":    &Impl.Backend_Neo4j{},
+		"postgres": &Impl.Backend_Postgres{},
+		"sqlite":   &Impl.Backend_SQLite{},
+		"bolt":     &Impl.Backend_Bolt{},
+	} {
+		if _, err := engine.Size(tree.Root.ThreadId, ctx); !errors.Is(err, Impl.ErrNotConnected) {
+			t.Errorf("%s: Size before Connect: got %v, want ErrNotConnected", name, err)
+		}
+		if err := engine.AddTree(tree.Root.ThreadId, tree, ctx); !errors.Is(err, Impl.ErrNotConnected) {
+			t.Errorf("%s: AddTree before Connect: got %v, want ErrNotConnected", name, err)
+		}
+		if _, err := Impl.ListThreads(engine, Impl.ListOptions{}, ctx); !errors.Is(err, Impl.ErrNotConnected) {
+			t.Errorf("%s: ListThreads before Connect: got %v, want ErrNotConnected", name, err)
+		}
+	}
+}
+
+// the memory backend needs no Connect but cannot be used after Close, not even to write
+func TestLifecycleMemory(t *testing.T) {
+	ctx := context.Background()
+	backend := &Impl.Backend_Memory{}
+	threadId := Impl.GetDemoTree().Root.ThreadId
+	if err := backend.AddTree(threadId, *Impl.GetDemoTree(), ctx); err != nil {
+		t.Fatalf("AddTree: %v", err)
+	}
+	backend.Close()
+	if _, err := backend.Size(threadId, ctx); !errors.Is(err, Impl.ErrNotConnected) {
+		t.Errorf("Size after Close: got %v, want ErrNotConnected", err)
+	}
+	if err := backend.AddTree(threadId, *Impl.GetDemoTree(), ctx); !errors.Is(err, Impl.ErrNotConnected) {
+		t.Errorf("AddTree after Close: got %v, want ErrNotConnected", err)
+	}
+	if _, err := Impl.ListThreads(backend, Impl.ListOptions{}, ctx); !errors.Is(err, Impl.ErrNotConnected) {
+		t.Errorf("ListThreads after Close: got %v, want ErrNotConnected", err)
+	}
+
+	if err := backend.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if _, err := backend.Size(threadId, ctx); !errors.Is(err, Impl.ErrThreadNotFound) {
+		t.Errorf("Size after Connect: got %v, want ErrThreadNotFound", err)
+	}
+}
@@ -0,0 +1,185 @@
+package impl
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+/*
+Lister is implemented by every backend and by the http and gRPC clients, it is kept out of TreeEngine like Lifecycle.
+The backends keep the time of the last activity of every thread next to it, so a page is a single ordered scan of the
+threads that starts after the cursor and stops after the limit. Only the threads on the page get their size, depth
+and latest message looked up, in the same query.
+
+	page, err := Impl.ListThreads(engine, Impl.ListOptions{OrderBy: Impl.ListByActivity}, ctx)
+	for page.NextCursor != "" {
+		page, err = Impl.ListThreads(engine, Impl.ListOptions{OrderBy: Impl.ListByActivity, Cursor: page.NextCursor}, ctx)
+	}
+*/
+type Lister interface {
+	ListThreads(opts ListOptions, ctx context.Context) (ThreadPage, error)
+}
+
+// ThreadSummary describes a stored thread, the times come from the created_at of its messages and are zero when none
+// of them has one
+type ThreadSummary struct {
+	ThreadId  string    `json:"thread_id"`
+	Size      int       `json:"size"`
+	Depth     int       `json:"depth"`
+	CreatedAt time.Time `json:"created_at"` // of the oldest message
+	UpdatedAt time.Time `json:"updated_at"` // of the newest message, the last activity
+	Latest    *Message  `json:"latest,omitempty"`
+}
+
+// orders of ListOptions.OrderBy
+const (
+	ListByThreadId = "thread_id" // thread ids in ascending order, the default
+	ListByActivity = "activity"  // the most recently updated threads first
+)
+
+// default and maximum ListOptions.Limit
+const (
+	defaultListLimit = 100
+	maxListLimit     = 1000
+)
+
+// ListOptions pick the page of ListThreads
+type ListOptions struct {
+	// Limit is the number of threads on a page, 100 when 0 and at most 1000
+	Limit int `json:"limit,omitempty"`
+
+	// Cursor is the NextCursor of the previous page, empty for the first one
+	Cursor string `json:"cursor,omitempty"`
+
+	// OrderBy is ListByThreadId or ListByActivity, a cursor only works with the order it was made for
+	OrderBy string `json:"order_by,omitempty"`
+
+	// Metadata keeps the threads with a message whose metadata has all of these keys with equal values
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// ThreadPage is a page of ListThreads, NextCursor is empty on the last page
+type ThreadPage struct {
+	Threads    []ThreadSummary `json:"threads"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+// ListThreads lists the threads of `engine`, errors.ErrUnsupported when it does not implement Lister
+func ListThreads(engine TreeEngine, opts ListOptions, ctx context.Context) (ThreadPage, error) {
+	if l, ok := engine.(Lister); ok {
+		return l.ListThreads(opts, ctx)
+	}
+	return ThreadPage{}, fmt.Errorf("%w: the engine cannot list threads", errors.ErrUnsupported)
+}
+
+// listCursor is where a page ends, it is sent as base64 encoded JSON
+type listCursor struct {
+	OrderBy   string    `json:"o"`
+	ThreadId  string    `json:"t"`
+	UpdatedAt time.Time `json:"u,omitempty"`
+}
+
+func (c listCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s, orderBy string) (listCursor, error) {
+	c := listCursor{}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(data, &c)
+	}
+	if err != nil || c.OrderBy != orderBy {
+		return c, fmt.Errorf("%w: %q is not a cursor for the %s order", ErrInvalidListOptions, s, orderBy)
+	}
+	return c, nil
+}
+
+// listQuery is a validated ListOptions with the cursor decoded, `after` is nil for the first page
+type listQuery struct {
+	ListOptions
+	after *listCursor
+}
+
+// newListQuery fills in the defaults and checks the rest
+func newListQuery(opts ListOptions) (listQuery, error) {
+	q := listQuery{ListOptions: opts}
+	if q.OrderBy == "" {
+		q.OrderBy = ListByThreadId
+	} else if q.OrderBy != ListByThreadId && q.OrderBy != ListByActivity {
+		return q, fmt.Errorf("%w: unknown order %q", ErrInvalidListOptions, q.OrderBy)
+	}
+	if q.Limit < 0 || q.Limit > maxListLimit {
+		return q, fmt.Errorf("%w: limit has to be within 0..%d", ErrInvalidListOptions, maxListLimit)
+	} else if q.Limit == 0 {
+		q.Limit = defaultListLimit
+	}
+	if q.Cursor != "" {
+		c, err := decodeCursor(q.Cursor, q.OrderBy)
+		if err != nil {
+			return q, err
+		}
+		q.after = &c
+	}
+	return q, nil
+}
+
+// follows tells if `s` comes after the cursor
+func (q listQuery) follows(s ThreadSummary) bool {
+	return q.after == nil || before(ThreadSummary{ThreadId: q.after.ThreadId, UpdatedAt: q.after.UpdatedAt}, s, q.OrderBy)
+}
+
+// page makes the ThreadPage from the threads that follow the cursor in order, the backends read upto Limit+1 of them
+// so a next page is only announced when there is one
+func (q listQuery) page(threads []ThreadSummary) ThreadPage {
+	page := ThreadPage{Threads: threads}
+	if len(threads) > q.Limit {
+		last := threads[q.Limit-1]
+		page.Threads = threads[:q.Limit]
+		page.NextCursor = listCursor{OrderBy: q.OrderBy, ThreadId: last.ThreadId, UpdatedAt: last.UpdatedAt}.encode()
+	}
+	return page
+}
+
+// before tells if `a` comes before `b` in the order, threads without a time come last by activity
+func before(a, b ThreadSummary, orderBy string) bool {
+	if orderBy == ListByActivity && !a.UpdatedAt.Equal(b.UpdatedAt) {
+		return a.UpdatedAt.After(b.UpdatedAt)
+	}
+	return a.ThreadId < b.ThreadId
+}
+
+// metadataFilter returns the JSON encoding of every value of the filter
+func metadataFilter(filter map[string]any) (map[string]string, error) {
+	encoded := map[string]string{}
+	for k, v := range filter {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: metadata filter %s: %v", ErrInvalidListOptions, k, err)
+		}
+		encoded[k] = string(data)
+	}
+	return encoded, nil
+}
+
+// metadataMatches tells if `metadata` has every key of `filter` with a value that encodes to the same JSON
+func metadataMatches(metadata, filter map[string]any) bool {
+	for k, want := range filter {
+		got, ok := metadata[k]
+		if !ok {
+			return false
+		}
+		a, errA := json.Marshal(got)
+		b, errB := json.Marshal(want)
+		if errA != nil || errB != nil || !bytes.Equal(a, b) {
+			return false
+		}
+	}
+	return true
+}
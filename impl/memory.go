@@ -0,0 +1,550 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Backend_Memory keeps every thread in process memory. It is safe for concurrent use and is meant for tests and local
+// development where running a database is overkill. The zero value is ready to use.
+type Backend_Memory struct {
+	mu      sync.RWMutex
+	threads map[string]*memoryThread
+	closed  bool
+}
+
+// memoryThread stores a single tree, the root is represented by the empty message id.
+type memoryThread struct {
+	messages map[string]Message
+	order    []string
+	parent   map[string]string
+	children map[string][]string
+
+	// oldest and newest created_at of the messages, zero when none has one
+	created, updated time.Time
+}
+
+// maximum number of hops Pick will walk, same as the Neo4j backend
+const maxPickLength = 40
+
+func newMemoryThread() *memoryThread {
+	return &memoryThread{
+		messages: map[string]Message{},
+		parent:   map[string]string{},
+		children: map[string][]string{},
+	}
+}
+
+func (t *memoryThread) add(m Message, parentId string) {
+	t.store(m)
+	t.link(parentId, m.MessageId)
+}
+
+func (t *memoryThread) store(m Message) {
+	t.messages[m.MessageId] = m.clone()
+	t.order = append(t.order, m.MessageId)
+	t.touch(m.CreatedAt)
+}
+
+// touch moves the created and updated times of the thread out to include `createdAt`
+func (t *memoryThread) touch(createdAt time.Time) {
+	if createdAt.IsZero() {
+		return
+	}
+	if t.created.IsZero() || createdAt.Before(t.created) {
+		t.created = createdAt
+	}
+	if createdAt.After(t.updated) {
+		t.updated = createdAt
+	}
+}
+
+func (t *memoryThread) link(parentId, childId string) {
+	t.parent[childId] = parentId
+	t.children[parentId] = append(t.children[parentId], childId)
+}
+
+// walk visits the nodes below `startId` breadth first, upto `levels` levels deep (-1 for no limit)
+func (t *memoryThread) walk(startId string, levels int, visit func(parentId, childId string)) {
+	frontier := []string{startId}
+	for level := 0; len(frontier) > 0 && (levels < 0 || level < levels); level++ {
+		next := []string{}
+		for _, p := range frontier {
+			for _, c := range t.children[p] {
+				visit(p, c)
+				next = append(next, c)
+			}
+		}
+		frontier = next
+	}
+}
+
+func (t *memoryThread) depth() int {
+	output := 0
+	frontier := t.children[""]
+	for len(frontier) > 0 {
+		output++
+		next := []string{}
+		for _, c := range frontier {
+			next = append(next, t.children[c]...)
+		}
+		frontier = next
+	}
+	return output
+}
+
+func (t *memoryThread) latest() (Message, bool) {
+	for _, id := range t.order {
+		if m := t.messages[id]; m.Latest {
+			return m.clone(), true
+		}
+	}
+	return Message{}, false
+}
+
+func (db *Backend_Memory) thread(threadId string) *memoryThread {
+	if db.threads == nil {
+		return nil
+	}
+	return db.threads[threadId]
+}
+
+// missing is the error to return when thread() finds nothing
+func (db *Backend_Memory) missing(threadId string) error {
+	if db.closed {
+		return ErrNotConnected
+	}
+	return threadNotFound(threadId)
+}
+
+// message returns the stored message `id`, or the error to return when it is missing
+func (t *memoryThread) message(threadId, id string) (Message, error) {
+	m, ok := t.messages[id]
+	if !ok {
+		return m, messageNotFound(threadId, id)
+	}
+	return m.clone(), nil
+}
+
+func init() {
+	Register("memory", func(ctx context.Context, u *url.URL) (TreeEngine, error) {
+		return &Backend_Memory{}, nil
+	})
+}
+
+// Connect is only needed after Close, it reopens the engine without any threads. The zero value is ready to use.
+func (db *Backend_Memory) Connect(ctx context.Context) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.closed = false
+	return nil
+}
+
+// Close drops every thread, the engine returns ErrNotConnected afterwards
+func (db *Backend_Memory) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.threads = nil
+	db.closed = true
+	return nil
+}
+
+func (db *Backend_Memory) Ping(ctx context.Context) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	if db.closed {
+		return ErrNotConnected
+	}
+	return nil
+}
+
+func (db *Backend_Memory) Info(ctx context.Context) (BackendInfo, error) {
+	if err := db.Ping(ctx); err != nil {
+		return BackendInfo{}, err
+	}
+	return BackendInfo{Name: "memory", Capabilities: []string{}}, nil
+}
+
+// implement interface
+
+func (db *Backend_Memory) AddMessage(threadId string, a, b *Message, ctx context.Context) error {
+	if a == nil || a.MessageId == "" {
+		return fmt.Errorf("%w: message to be inserted cannot be empty", ErrInvalidMessage)
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	t := db.thread(threadId)
+	if t == nil {
+		return db.missing(threadId)
+	}
+	parentId := ""
+	if b != nil {
+		parentId = b.MessageId
+		if _, err := t.message(threadId, parentId); err != nil {
+			return err
+		}
+	}
+	if _, ok := t.messages[a.MessageId]; ok {
+		return duplicateMessage(threadId, a.MessageId)
+	}
+	// the latest message is only changed through SetLatestMessage
+	payload := *a
+	payload.Latest = false
+	t.add(payload, parentId)
+	return nil
+}
+
+func (db *Backend_Memory) AddTree(threadId string, tree ThreadTree, ctx context.Context) error {
+	if err := validateTree(threadId, tree); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.closed {
+		return ErrNotConnected
+	}
+	t := db.thread(threadId)
+	if t == nil {
+		t = newMemoryThread()
+	}
+
+	// check against the stored thread before touching it so a bad tree leaves no trace
+	inTree := map[string]bool{}
+	for _, m := range tree.Messages {
+		inTree[m.MessageId] = true
+	}
+	for _, r := range tree.Relations {
+		if _, stored := t.messages[r.StartId]; r.StartId != "" && !inTree[r.StartId] && !stored {
+			return invalidTree("relation starts from unknown message %s", r.StartId)
+		}
+		p, stored := t.parent[r.EndId]
+		if !inTree[r.EndId] && !stored {
+			return invalidTree("relation ends at unknown message %s", r.EndId)
+		} else if stored && p != r.StartId {
+			return invalidTree("message %s already has a parent", r.EndId)
+		}
+	}
+
+	for _, m := range tree.Messages {
+		if _, ok := t.messages[m.MessageId]; !ok {
+			t.store(m)
+		}
+	}
+	for _, r := range tree.Relations {
+		if _, ok := t.parent[r.EndId]; !ok {
+			t.link(r.StartId, r.EndId)
+		}
+	}
+
+	if db.threads == nil {
+		db.threads = map[string]*memoryThread{}
+	}
+	db.threads[threadId] = t
+	return nil
+}
+
+func (db *Backend_Memory) Breadth(threadId string, ctx context.Context) (int, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	t := db.thread(threadId)
+	if t == nil {
+		return 0, db.missing(threadId)
+	}
+	output := 0
+	t.walk("", -1, func(_, c string) {
+		if len(t.children[c]) == 0 {
+			output++
+		}
+	})
+	return output, nil
+}
+
+func (db *Backend_Memory) Degree(threadId string, message *Message, ctx context.Context) (int, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	t := db.thread(threadId)
+	if t == nil {
+		return 0, db.missing(threadId)
+	}
+	startId := ""
+	if message != nil {
+		startId = message.MessageId
+		if _, err := t.message(threadId, startId); err != nil {
+			return 0, err
+		}
+	}
+	return len(t.children[startId]), nil
+}
+
+func (db *Backend_Memory) Delete(threadId string, message *Message, ctx context.Context) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	t := db.thread(threadId)
+	if t == nil {
+		return db.missing(threadId)
+	}
+	if message == nil {
+		delete(db.threads, threadId)
+		return nil
+	}
+	if _, err := t.message(threadId, message.MessageId); err != nil {
+		return err
+	}
+
+	removed := map[string]bool{message.MessageId: true}
+	t.walk(message.MessageId, -1, func(_, c string) { removed[c] = true })
+
+	// detach from the parent
+	parentId := t.parent[message.MessageId]
+	siblings := []string{}
+	for _, c := range t.children[parentId] {
+		if c != message.MessageId {
+			siblings = append(siblings, c)
+		}
+	}
+	t.children[parentId] = siblings
+
+	order := []string{}
+	for _, id := range t.order {
+		if !removed[id] {
+			order = append(order, id)
+		}
+	}
+	t.order = order
+	for id := range removed {
+		delete(t.messages, id)
+		delete(t.parent, id)
+		delete(t.children, id)
+	}
+	t.created, t.updated = time.Time{}, time.Time{}
+	for _, m := range t.messages {
+		t.touch(m.CreatedAt)
+	}
+	return nil
+}
+
+func (db *Backend_Memory) Depth(threadId string, ctx context.Context) (int, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	t := db.thread(threadId)
+	if t == nil {
+		return 0, db.missing(threadId)
+	}
+	return t.depth(), nil
+}
+
+func (db *Backend_Memory) Get(threadId string, ctx context.Context) (ThreadTree, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	output := ThreadTree{}
+	t := db.thread(threadId)
+	if t == nil {
+		return output, db.missing(threadId)
+	}
+	output.Root = ThreadRoot{ThreadId: threadId}
+	t.walk("", -1, func(p, c string) {
+		output.Messages = append(output.Messages, t.messages[c].clone())
+		output.Relations = append(output.Relations, Triple{StartId: p, Relation: "CHILD", EndId: c})
+	})
+	return output, nil
+}
+
+func (db *Backend_Memory) GetChildren(threadId string, message *Message, depth int, ctx context.Context) (ThreadTree, error) {
+	output := ThreadTree{}
+	if err := validateDepth(depth); err != nil {
+		return output, err
+	} else if depth == 1 {
+		depth = 2
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	t := db.thread(threadId)
+	if t == nil {
+		return output, db.missing(threadId)
+	}
+	output.Root = ThreadRoot{ThreadId: threadId}
+	startId := ""
+	if message != nil {
+		m, err := t.message(threadId, message.MessageId)
+		if err != nil {
+			return ThreadTree{}, err
+		}
+		startId = m.MessageId
+		output.Messages = append(output.Messages, m)
+	}
+	t.walk(startId, depth-1, func(p, c string) {
+		output.Messages = append(output.Messages, t.messages[c].clone())
+		output.Relations = append(output.Relations, Triple{StartId: p, Relation: "CHILD", EndId: c})
+	})
+	return output, nil
+}
+
+func (db *Backend_Memory) GetLatestMessage(threadId string, ctx context.Context) (Message, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	t := db.thread(threadId)
+	if t == nil {
+		return Message{}, db.missing(threadId)
+	}
+	if m, ok := t.latest(); ok {
+		return m, nil
+	}
+	return Message{}, ErrNoLatest
+}
+
+func (db *Backend_Memory) Pick(threadId string, a *Message, b *Message, ctx context.Context) (Thread, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	output := Thread{}
+	t := db.thread(threadId)
+	if t == nil {
+		return output, db.missing(threadId)
+	}
+
+	startId := ""
+	if a != nil {
+		startId = a.MessageId
+		if _, err := t.message(threadId, startId); err != nil {
+			return output, err
+		}
+	}
+	var end Message
+	if b == nil {
+		m, ok := t.latest()
+		if !ok {
+			return output, ErrNoLatest
+		}
+		end = m
+	} else {
+		m, err := t.message(threadId, b.MessageId)
+		if err != nil {
+			return output, err
+		}
+		end = m
+	}
+
+	// walk up the parents from the end till we hit the start
+	path := []Message{end}
+	for id := end.MessageId; ; {
+		parentId, ok := t.parent[id]
+		if !ok {
+			// the start is not above the end
+			return output, nil
+		}
+		if parentId == startId {
+			break
+		}
+		path = append(path, t.messages[parentId].clone())
+		id = parentId
+	}
+	if a != nil {
+		path = append(path, t.messages[startId].clone())
+	}
+	hops := len(path)
+	if a != nil {
+		hops--
+	}
+	if hops > maxPickLength {
+		return output, pickTooLong()
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		output.Messages = append(output.Messages, path[i])
+	}
+	return output, nil
+}
+
+func (db *Backend_Memory) SetLatestMessage(threadId string, latestMessage *Message, ctx context.Context) (Message, error) {
+	if latestMessage == nil {
+		return Message{}, fmt.Errorf("%w: latest message cannot be empty", ErrInvalidMessage)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	t := db.thread(threadId)
+	if t == nil {
+		return Message{}, db.missing(threadId)
+	}
+	if _, err := t.message(threadId, latestMessage.MessageId); err != nil {
+		return Message{}, err
+	}
+	for id, m := range t.messages {
+		m.Latest = id == latestMessage.MessageId
+		t.messages[id] = m
+	}
+	return t.messages[latestMessage.MessageId].clone(), nil
+}
+
+func (db *Backend_Memory) Size(threadId string, ctx context.Context) (int, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	t := db.thread(threadId)
+	if t == nil {
+		return 0, db.missing(threadId)
+	}
+	output := 0
+	t.walk("", -1, func(_, _ string) { output++ })
+	return output, nil
+}
+
+// ListThreads sorts the threads by the times kept with them, only the ones on the page are looked into
+func (db *Backend_Memory) ListThreads(opts ListOptions, ctx context.Context) (ThreadPage, error) {
+	q, err := newListQuery(opts)
+	if err != nil {
+		return ThreadPage{}, err
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return ThreadPage{}, ErrNotConnected
+	}
+	threads := []ThreadSummary{}
+	for threadId, t := range db.threads {
+		s := ThreadSummary{ThreadId: threadId, CreatedAt: t.created, UpdatedAt: t.updated}
+		if q.follows(s) && t.matches(q.Metadata) {
+			threads = append(threads, s)
+		}
+	}
+	sort.Slice(threads, func(i, j int) bool { return before(threads[i], threads[j], q.OrderBy) })
+	threads = threads[:min(len(threads), q.Limit+1)]
+	for i, s := range threads {
+		t := db.threads[s.ThreadId]
+		threads[i].Size = len(t.messages)
+		threads[i].Depth = t.depth()
+		if m, ok := t.latest(); ok {
+			threads[i].Latest = &m
+		}
+	}
+	return q.page(threads), nil
+}
+
+// matches tells if a message of the thread passes the metadata filter
+func (t *memoryThread) matches(metadata map[string]any) bool {
+	if len(metadata) == 0 {
+		return true
+	}
+	for _, m := range t.messages {
+		if metadataMatches(m.Metadata, metadata) {
+			return true
+		}
+	}
+	return false
+}
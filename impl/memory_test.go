@@ -0,0 +1,14 @@
+package impl_test
+
+import (
+	"testing"
+
+	Impl "github.com/yashbonde/vriksham/impl"
+	"github.com/yashbonde/vriksham/impl/enginetest"
+)
+
+func TestBackendMemory(t *testing.T) {
+	enginetest.Run(t, func(t *testing.T) Impl.TreeEngine {
+		return &Impl.Backend_Memory{}
+	})
+}
@@ -0,0 +1,106 @@
+package impl_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	Impl "github.com/yashbonde/vriksham/impl"
+	"github.com/yashbonde/vriksham/impl/enginetest"
+)
+
+// neo4jBackend connects to a live database, set VRIKSHAM_NEO4J_URL (and _USER / _PASS) to enable the tests
+func neo4jBackend(t *testing.T) *Impl.Backend_Neo4j {
+	url := os.Getenv("VRIKSHAM_NEO4J_URL")
+	if url == "" {
+		t.Skip("VRIKSHAM_NEO4J_URL is not set")
+	}
+	backend, err := Impl.NewNeo4j(context.Background(), Impl.Neo4jConfig{
+		DbUrl:    url,
+		AuthUser: os.Getenv("VRIKSHAM_NEO4J_USER"),
+		AuthPass: os.Getenv("VRIKSHAM_NEO4J_PASS"),
+		Database: os.Getenv("VRIKSHAM_NEO4J_DATABASE"),
+	})
+	if err != nil {
+		t.Fatalf("NewNeo4j: %v", err)
+	}
+	return backend
+}
+
+// configs that are rejected before anything is dialed
+func TestNeo4jConfig(t *testing.T) {
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "ca.pem")
+	os.WriteFile(notPEM, []byte("not a certificate"), 0o600)
+
+	for _, config := range []Impl.Neo4jConfig{
+		{DbUrl: "http://localhost:7474"},
+		{DbUrl: "neo4j+tls://localhost"},
+		{DbUrl: "neo4j://localhost", CACertFile: notPEM},
+		{DbUrl: "neo4j+s://localhost", CACertFile: filepath.Join(dir, "missing.pem")},
+		{DbUrl: "bolt+s://localhost", CACertFile: notPEM},
+	} {
+		if _, err := Impl.NewNeo4j(context.Background(), config); err == nil {
+			t.Errorf("NewNeo4j(%+v): expected an error", config)
+		}
+	}
+	// a backend set up field by field reads the url from its own field and the rest from Config
+	for _, backend := range []*Impl.Backend_Neo4j{
+		{DbUrl: "http://localhost:7474"},
+		{DbUrl: "neo4j+s://localhost", Config: Impl.Neo4jConfig{DbUrl: "neo4j://localhost", CACertFile: notPEM}},
+	} {
+		if err := backend.Connect(context.Background()); err == nil {
+			t.Errorf("Connect(%+v): expected an error", backend)
+		}
+	}
+}
+
+func TestBackendNeo4j(t *testing.T) {
+	enginetest.Run(t, func(t *testing.T) Impl.TreeEngine {
+		backend := neo4jBackend(t)
+		backend.Delete(Impl.GetDemoTree().Root.ThreadId, nil, context.Background())
+		return backend
+	})
+}
+
+func TestMigrateNeo4j(t *testing.T) {
+	ctx := context.Background()
+	backend := neo4jBackend(t)
+	defer backend.Close()
+	from, to, err := backend.Migrate(ctx)
+	if err != nil || from != to || to != 4 {
+		t.Errorf("Migrate: got %d to %d, %v, want 4 to 4 after Connect", from, to, err)
+	}
+}
+
+// a tree larger than a batch, half of it hanging from the root and half in a chain
+func TestBulkAddTreeNeo4j(t *testing.T) {
+	ctx := context.Background()
+	backend := neo4jBackend(t)
+	threadId := "bulk_thread"
+	backend.Delete(threadId, nil, ctx)
+	defer backend.Delete(threadId, nil, ctx)
+
+	tree := Impl.ThreadTree{Root: Impl.ThreadRoot{ThreadId: threadId}}
+	parentId := ""
+	for i := 0; i < 2500; i++ {
+		id := fmt.Sprintf("bulk_%04d", i)
+		tree.Messages = append(tree.Messages, Impl.Message{MessageId: id, Content: id})
+		tree.Relations = append(tree.Relations, Impl.Triple{StartId: parentId, Relation: "CHILD", EndId: id})
+		if i >= 1250 {
+			parentId = id
+		}
+	}
+	summary, err := backend.BulkAddTree(threadId, tree, ctx)
+	if err != nil {
+		t.Fatalf("BulkAddTree: %v", err)
+	}
+	if want := (Impl.AddTreeSummary{NodesCreated: 2501, RelationshipsCreated: 2500}); summary != want {
+		t.Errorf("BulkAddTree: got %+v, want %+v", summary, want)
+	}
+	if size, err := backend.Size(threadId, ctx); err != nil || size != 2500 {
+		t.Errorf("Size: got %d, %v", size, err)
+	}
+}
@@ -0,0 +1,833 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+/*
+Backend_Postgres stores threads in PostgreSQL. Every message row points to its parent (NULL for the children of the
+ThreadRoot) and the tree questions are answered with `WITH RECURSIVE` queries over the (thread_id, parent_id) index.
+Deleting a message cascades to everything below it through the parent foreign key.
+
+The schema is created and migrated by Connect.
+*/
+type Backend_Postgres struct {
+	DbUrl string `json:"db_url"`
+	pool  *pgxpool.Pool
+}
+
+// postgresMigrations are applied in order, applied versions are recorded in `vriksham_migrations`
+var postgresMigrations = []string{
+	`
+	CREATE TABLE vriksham_threads (
+		thread_id TEXT PRIMARY KEY
+	);
+	CREATE TABLE vriksham_messages (
+		thread_id TEXT NOT NULL REFERENCES vriksham_threads (thread_id) ON DELETE CASCADE,
+		id        TEXT NOT NULL,
+		parent_id TEXT,
+		latest    BOOLEAN NOT NULL DEFAULT false,
+		seq       BIGSERIAL,
+		PRIMARY KEY (thread_id, id),
+		FOREIGN KEY (thread_id, parent_id) REFERENCES vriksham_messages (thread_id, id)
+			ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED
+	);
+	CREATE INDEX vriksham_messages_parent ON vriksham_messages (thread_id, parent_id);
+	CREATE INDEX vriksham_messages_latest ON vriksham_messages (thread_id) WHERE latest;
+	`,
+	`
+	ALTER TABLE vriksham_messages
+		ADD COLUMN role       TEXT NOT NULL DEFAULT '',
+		ADD COLUMN content    TEXT NOT NULL DEFAULT '',
+		ADD COLUMN author     TEXT NOT NULL DEFAULT '',
+		ADD COLUMN created_at TIMESTAMPTZ,
+		ADD COLUMN metadata   JSONB;
+	`,
+	`
+	ALTER TABLE vriksham_messages
+		ADD COLUMN thumbs SMALLINT,
+		ADD COLUMN score  DOUBLE PRECISION;
+	`,
+	// the newest created_at of every thread, ListThreads orders by it
+	`
+	ALTER TABLE vriksham_threads ADD COLUMN updated_at TIMESTAMPTZ;
+	UPDATE vriksham_threads t
+	SET updated_at = (SELECT MAX(m.created_at) FROM vriksham_messages m WHERE m.thread_id = t.thread_id);
+	CREATE INDEX vriksham_threads_activity ON vriksham_threads (updated_at DESC NULLS LAST, thread_id);
+
+	CREATE FUNCTION vriksham_message_added() RETURNS trigger LANGUAGE plpgsql AS $$
+	BEGIN
+		UPDATE vriksham_threads SET updated_at = NEW.created_at
+		WHERE thread_id = NEW.thread_id AND (updated_at IS NULL OR updated_at < NEW.created_at);
+		RETURN NULL;
+	END
+	$$;
+	CREATE TRIGGER vriksham_message_added AFTER INSERT ON vriksham_messages
+		FOR EACH ROW WHEN (NEW.created_at IS NOT NULL) EXECUTE PROCEDURE vriksham_message_added();
+
+	CREATE FUNCTION vriksham_message_removed() RETURNS trigger LANGUAGE plpgsql AS $$
+	BEGIN
+		UPDATE vriksham_threads t
+		SET updated_at = (SELECT MAX(m.created_at) FROM vriksham_messages m WHERE m.thread_id = t.thread_id)
+		WHERE t.thread_id = OLD.thread_id AND t.updated_at <= OLD.created_at;
+		RETURN NULL;
+	END
+	$$;
+	CREATE TRIGGER vriksham_message_removed AFTER DELETE ON vriksham_messages
+		FOR EACH ROW WHEN (OLD.created_at IS NOT NULL) EXECUTE PROCEDURE vriksham_message_removed();
+	`,
+}
+
+// postgresMessageColumns are the columns read by postgresScanMessage, the messages table is always aliased as `m`
+const postgresMessageColumns = "m.id, m.latest, m.role, m.content, m.author, m.created_at, m.metadata, m.thumbs, m.score"
+
+// postgresOptionalMessageColumns are postgresMessageColumns for a left joined `m`, no message is read with an empty id
+const postgresOptionalMessageColumns = "COALESCE(m.id, ''), COALESCE(m.latest, false), COALESCE(m.role, ''), " +
+	"COALESCE(m.content, ''), COALESCE(m.author, ''), m.created_at, m.metadata, m.thumbs, m.score"
+
+// postgresScanMessage reads the postgresMessageColumns followed by the `extra` columns
+func postgresScanMessage(row pgx.Row, extra ...any) (Message, error) {
+	m := Message{}
+	var createdAt *time.Time
+	var metadata []byte
+	var thumbs *int16
+	var score *float64
+	dest := append([]any{&m.MessageId, &m.Latest, &m.Role, &m.Content, &m.Author, &createdAt, &metadata, &thumbs, &score}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return m, err
+	}
+	if createdAt != nil {
+		m.CreatedAt = *createdAt
+	}
+	if thumbs != nil {
+		m.Rating = &Rating{Thumbs: int(*thumbs)}
+		if score != nil {
+			m.Rating.Score = *score
+		}
+	}
+	data, err := decodeMetadata(string(metadata))
+	m.Metadata = data
+	return m, err
+}
+
+// postgresPayload returns the role, content, author, created_at, metadata, thumbs and score values to store for `m`
+func postgresPayload(m Message) ([]any, error) {
+	var createdAt, metadata, thumbs, score any
+	if !m.CreatedAt.IsZero() {
+		createdAt = m.CreatedAt
+	}
+	if len(m.Metadata) > 0 {
+		data, err := encodeMetadata(m.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		metadata = data
+	}
+	if m.Rating != nil {
+		thumbs, score = int16(m.Rating.Thumbs), m.Rating.Score
+	}
+	return []any{m.Role, m.Content, m.Author, createdAt, metadata, thumbs, score}, nil
+}
+
+// postgresMigrationLock is the advisory lock key held while migrating, so concurrent Connect calls are safe
+const postgresMigrationLock = 0x7672696b7368616d
+
+func init() {
+	for _, scheme := range []string{"postgres", "postgresql"} {
+		Register(scheme, func(ctx context.Context, u *url.URL) (TreeEngine, error) {
+			backend := &Backend_Postgres{DbUrl: u.String()}
+			return backend, backend.Connect(ctx)
+		})
+	}
+}
+
+func (backend *Backend_Postgres) Connect(ctx context.Context) error {
+	pool, err := pgxpool.New(ctx, backend.DbUrl)
+	if err != nil {
+		return err
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return err
+	}
+	if _, _, err := postgresMigrate(ctx, pool); err != nil {
+		pool.Close()
+		return err
+	}
+	backend.pool = pool
+	return nil
+}
+
+func (backend *Backend_Postgres) Close() error {
+	if backend.pool != nil {
+		backend.pool.Close()
+		backend.pool = nil
+	}
+	return nil
+}
+
+// connected is ErrNotConnected before Connect and after Close, every method checks it before using the database
+func (db Backend_Postgres) connected() error {
+	if db.pool == nil {
+		return ErrNotConnected
+	}
+	return nil
+}
+
+func (db Backend_Postgres) Ping(ctx context.Context) error {
+	if err := db.connected(); err != nil {
+		return err
+	}
+	return db.pool.Ping(ctx)
+}
+
+func (db Backend_Postgres) Info(ctx context.Context) (BackendInfo, error) {
+	info := BackendInfo{Name: "postgres", Capabilities: []string{CapabilityPersistent, CapabilityShared}}
+	if err := db.connected(); err != nil {
+		return info, err
+	}
+	err := db.pool.QueryRow(ctx, "SHOW server_version").Scan(&info.Version)
+	return info, err
+}
+
+func postgresMigrate(ctx context.Context, pool *pgxpool.Pool) (int, int, error) {
+	from, to := 0, 0
+	err := pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", int64(postgresMigrationLock)); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+			CREATE TABLE IF NOT EXISTS vriksham_migrations (
+				version    INTEGER PRIMARY KEY,
+				applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+			)
+		`); err != nil {
+			return err
+		}
+		if err := tx.QueryRow(ctx, "SELECT COALESCE(MAX(version), 0) FROM vriksham_migrations").Scan(&from); err != nil {
+			return err
+		}
+		to = max(from, len(postgresMigrations))
+		for i := from; i < len(postgresMigrations); i++ {
+			if _, err := tx.Exec(ctx, postgresMigrations[i]); err != nil {
+				return fmt.Errorf("migration %d: %w", i+1, err)
+			}
+			if _, err := tx.Exec(ctx, "INSERT INTO vriksham_migrations (version) VALUES ($1)", i+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		// nothing was applied
+		return from, from, err
+	}
+	return from, to, nil
+}
+
+func (db Backend_Postgres) Migrate(ctx context.Context) (int, int, error) {
+	if err := db.connected(); err != nil {
+		return 0, 0, err
+	}
+	return postgresMigrate(ctx, db.pool)
+}
+
+// postgresChildrenOf returns the condition matching the children of `startId` (the root when empty), adding the
+// parameter it needs to `args`
+func postgresChildrenOf(startId string, args []any) (string, []any) {
+	if startId == "" {
+		return "parent_id IS NULL", args
+	}
+	args = append(args, startId)
+	return fmt.Sprintf("parent_id = $%d", len(args)), args
+}
+
+func (db Backend_Postgres) count(ctx context.Context, query string, args ...any) (int, error) {
+	output := 0
+	err := db.pool.QueryRow(ctx, query, args...).Scan(&output)
+	return output, err
+}
+
+// subtree returns the messages below `startId` (upto `levels` deep, -1 for no limit) along with their relations
+func (db Backend_Postgres) subtree(ctx context.Context, threadId, startId string, levels int) ([]Message, []Triple, error) {
+	anchor, args := postgresChildrenOf(startId, []any{threadId, levels})
+	rows, err := db.pool.Query(
+		ctx,
+		`
+		WITH RECURSIVE tree AS (
+			SELECT id, 1 AS depth
+			FROM vriksham_messages
+			WHERE thread_id = $1 AND `+anchor+`
+			UNION ALL
+			SELECT m.id, t.depth + 1
+			FROM vriksham_messages m
+			JOIN tree t ON m.thread_id = $1 AND m.parent_id = t.id
+			WHERE $2 < 0 OR t.depth < $2
+		)
+		SELECT `+postgresMessageColumns+`, COALESCE(m.parent_id, '')
+		FROM tree t
+		JOIN vriksham_messages m ON m.thread_id = $1 AND m.id = t.id
+		ORDER BY t.depth, m.seq
+		`,
+		args...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	messages := []Message{}
+	relations := []Triple{}
+	for rows.Next() {
+		parentId := ""
+		m, err := postgresScanMessage(rows, &parentId)
+		if err != nil {
+			return nil, nil, err
+		}
+		messages = append(messages, m)
+		relations = append(relations, Triple{StartId: parentId, Relation: "CHILD", EndId: m.MessageId})
+	}
+	return messages, relations, rows.Err()
+}
+
+// postgresQuerier is either the pool or an open transaction
+type postgresQuerier interface {
+	QueryRow(context.Context, string, ...any) pgx.Row
+}
+
+// checkThread returns ErrThreadNotFound when the thread is not stored
+func (db Backend_Postgres) checkThread(ctx context.Context, q postgresQuerier, threadId string) error {
+	found := false
+	err := q.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM vriksham_threads WHERE thread_id = $1)", threadId).Scan(&found)
+	if err != nil {
+		return err
+	} else if !found {
+		return threadNotFound(threadId)
+	}
+	return nil
+}
+
+// byId returns the message `id`, telling apart a missing thread from a missing message
+func (db Backend_Postgres) byId(ctx context.Context, q postgresQuerier, threadId, id string) (Message, error) {
+	m, err := postgresScanMessage(q.QueryRow(
+		ctx,
+		"SELECT "+postgresMessageColumns+" FROM vriksham_messages m WHERE m.thread_id = $1 AND m.id = $2",
+		threadId, id,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		if err := db.checkThread(ctx, q, threadId); err != nil {
+			return m, err
+		}
+		return m, messageNotFound(threadId, id)
+	}
+	return m, err
+}
+
+// countInThread runs a counting query, a zero count is checked against a missing thread
+func (db Backend_Postgres) countInThread(ctx context.Context, threadId, query string, args ...any) (int, error) {
+	output, err := db.count(ctx, query, args...)
+	if err == nil && output == 0 {
+		err = db.checkThread(ctx, db.pool, threadId)
+	}
+	return output, err
+}
+
+// latest returns the latest message, telling apart a missing thread from a thread without a latest message
+func (db Backend_Postgres) latest(ctx context.Context, threadId string) (Message, error) {
+	output, err := postgresScanMessage(db.pool.QueryRow(
+		ctx,
+		"SELECT "+postgresMessageColumns+" FROM vriksham_messages m WHERE m.thread_id = $1 AND m.latest ORDER BY m.seq LIMIT 1",
+		threadId,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		if err := db.checkThread(ctx, db.pool, threadId); err != nil {
+			return output, err
+		}
+		return output, ErrNoLatest
+	}
+	return output, err
+}
+
+// implement interface
+
+func (db Backend_Postgres) AddMessage(threadId string, a, b *Message, ctx context.Context) error {
+	if err := db.connected(); err != nil {
+		return err
+	}
+	if a == nil || a.MessageId == "" {
+		return fmt.Errorf("%w: message to be inserted cannot be empty", ErrInvalidMessage)
+	}
+	return pgx.BeginFunc(ctx, db.pool, func(tx pgx.Tx) error {
+		var parentId *string
+		if b != nil {
+			if _, err := db.byId(ctx, tx, threadId, b.MessageId); err != nil {
+				return err
+			}
+			parentId = &b.MessageId
+		} else if err := db.checkThread(ctx, tx, threadId); err != nil {
+			return err
+		}
+
+		// the latest message is only changed through SetLatestMessage
+		payload, err := postgresPayload(*a)
+		if err != nil {
+			return err
+		}
+		tag, err := tx.Exec(
+			ctx,
+			`INSERT INTO vriksham_messages (thread_id, id, parent_id, role, content, author, created_at, metadata, thumbs, score)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			ON CONFLICT (thread_id, id) DO NOTHING`,
+			append([]any{threadId, a.MessageId, parentId}, payload...)...,
+		)
+		if err != nil {
+			return err
+		} else if tag.RowsAffected() == 0 {
+			return duplicateMessage(threadId, a.MessageId)
+		}
+		return nil
+	})
+}
+
+func (db Backend_Postgres) AddTree(threadId string, tree ThreadTree, ctx context.Context) error {
+	if err := db.connected(); err != nil {
+		return err
+	}
+	if err := validateTree(threadId, tree); err != nil {
+		return err
+	}
+
+	// everything goes in one transaction, the parent foreign key is checked on commit so the order does not matter
+	return pgx.BeginFunc(ctx, db.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(
+			ctx,
+			"INSERT INTO vriksham_threads (thread_id) VALUES ($1) ON CONFLICT DO NOTHING",
+			threadId,
+		); err != nil {
+			return err
+		}
+
+		// load what already exists so merging a tree behaves the same as the other backends
+		existing := map[string]string{}
+		rows, err := tx.Query(ctx, "SELECT id, COALESCE(parent_id, '') FROM vriksham_messages WHERE thread_id = $1", threadId)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			id, parentId := "", ""
+			if err := rows.Scan(&id, &parentId); err != nil {
+				rows.Close()
+				return err
+			}
+			existing[id] = parentId
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		messages := map[string]Message{}
+		for _, m := range tree.Messages {
+			messages[m.MessageId] = m
+		}
+		batch := &pgx.Batch{}
+		for _, r := range tree.Relations {
+			_, inTree := messages[r.StartId]
+			_, stored := existing[r.StartId]
+			if r.StartId != "" && !inTree && !stored {
+				return invalidTree("relation starts from unknown message %s", r.StartId)
+			}
+			_, inTree = messages[r.EndId]
+			p, stored := existing[r.EndId]
+			if !inTree && !stored {
+				return invalidTree("relation ends at unknown message %s", r.EndId)
+			} else if stored && p != r.StartId {
+				return invalidTree("message %s already has a parent", r.EndId)
+			} else if stored {
+				continue
+			}
+
+			var parentId *string
+			if startId := r.StartId; startId != "" {
+				parentId = &startId
+			}
+			payload, err := postgresPayload(messages[r.EndId])
+			if err != nil {
+				return err
+			}
+			batch.Queue(
+				`INSERT INTO vriksham_messages (thread_id, id, parent_id, latest, role, content, author, created_at, metadata, thumbs, score)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+				append([]any{threadId, r.EndId, parentId, messages[r.EndId].Latest}, payload...)...,
+			)
+		}
+		return tx.SendBatch(ctx, batch).Close()
+	})
+}
+
+func (db Backend_Postgres) Breadth(threadId string, ctx context.Context) (int, error) {
+	if err := db.connected(); err != nil {
+		return 0, err
+	}
+	return db.countInThread(
+		ctx,
+		threadId,
+		`
+		SELECT COUNT(*) FROM vriksham_messages m
+		WHERE m.thread_id = $1
+		AND NOT EXISTS (SELECT 1 FROM vriksham_messages c WHERE c.thread_id = m.thread_id AND c.parent_id = m.id)
+		`,
+		threadId,
+	)
+}
+
+func (db Backend_Postgres) Degree(threadId string, message *Message, ctx context.Context) (int, error) {
+	if err := db.connected(); err != nil {
+		return 0, err
+	}
+	startId := ""
+	if message != nil {
+		if _, err := db.byId(ctx, db.pool, threadId, message.MessageId); err != nil {
+			return 0, err
+		}
+		startId = message.MessageId
+	}
+	where, args := postgresChildrenOf(startId, []any{threadId})
+	return db.countInThread(ctx, threadId, "SELECT COUNT(*) FROM vriksham_messages WHERE thread_id = $1 AND "+where, args...)
+}
+
+func (db Backend_Postgres) Delete(threadId string, message *Message, ctx context.Context) error {
+	if err := db.connected(); err != nil {
+		return err
+	}
+	var tag pgconn.CommandTag
+	var err error
+	if message == nil {
+		tag, err = db.pool.Exec(ctx, "DELETE FROM vriksham_threads WHERE thread_id = $1", threadId)
+	} else {
+		tag, err = db.pool.Exec(
+			ctx,
+			"DELETE FROM vriksham_messages WHERE thread_id = $1 AND id = $2",
+			threadId, message.MessageId,
+		)
+	}
+	if err != nil {
+		return err
+	} else if tag.RowsAffected() > 0 {
+		return nil
+	}
+	// nothing was deleted, find out what is missing
+	if err := db.checkThread(ctx, db.pool, threadId); err != nil || message == nil {
+		return err
+	}
+	return messageNotFound(threadId, message.MessageId)
+}
+
+func (db Backend_Postgres) Depth(threadId string, ctx context.Context) (int, error) {
+	if err := db.connected(); err != nil {
+		return 0, err
+	}
+	return db.countInThread(
+		ctx,
+		threadId,
+		`
+		WITH RECURSIVE tree AS (
+			SELECT id, 1 AS depth FROM vriksham_messages WHERE thread_id = $1 AND parent_id IS NULL
+			UNION ALL
+			SELECT m.id, t.depth + 1
+			FROM vriksham_messages m
+			JOIN tree t ON m.thread_id = $1 AND m.parent_id = t.id
+		)
+		SELECT COALESCE(MAX(depth), 0) FROM tree
+		`,
+		threadId,
+	)
+}
+
+func (db Backend_Postgres) Get(threadId string, ctx context.Context) (ThreadTree, error) {
+	if err := db.connected(); err != nil {
+		return ThreadTree{}, err
+	}
+	output := ThreadTree{}
+	messages, relations, err := db.subtree(ctx, threadId, "", -1)
+	if err != nil {
+		return output, err
+	} else if len(messages) == 0 {
+		if err := db.checkThread(ctx, db.pool, threadId); err != nil {
+			return output, err
+		}
+	}
+	output.Root = ThreadRoot{ThreadId: threadId}
+	output.Messages = messages
+	output.Relations = relations
+	return output, nil
+}
+
+func (db Backend_Postgres) GetChildren(threadId string, message *Message, depth int, ctx context.Context) (ThreadTree, error) {
+	if err := db.connected(); err != nil {
+		return ThreadTree{}, err
+	}
+	output := ThreadTree{}
+	if err := validateDepth(depth); err != nil {
+		return output, err
+	} else if depth == 1 {
+		depth = 2
+	}
+	startId := ""
+	if message != nil {
+		m, err := db.byId(ctx, db.pool, threadId, message.MessageId)
+		if err != nil {
+			return output, err
+		}
+		startId = m.MessageId
+		output.Messages = append(output.Messages, m)
+	} else if err := db.checkThread(ctx, db.pool, threadId); err != nil {
+		return output, err
+	}
+	messages, relations, err := db.subtree(ctx, threadId, startId, depth-1)
+	if err != nil {
+		return ThreadTree{}, err
+	}
+	output.Root = ThreadRoot{ThreadId: threadId}
+	output.Messages = append(output.Messages, messages...)
+	output.Relations = relations
+	return output, nil
+}
+
+func (db Backend_Postgres) GetLatestMessage(threadId string, ctx context.Context) (Message, error) {
+	if err := db.connected(); err != nil {
+		return Message{}, err
+	}
+	return db.latest(ctx, threadId)
+}
+
+func (db Backend_Postgres) Pick(threadId string, a *Message, b *Message, ctx context.Context) (Thread, error) {
+	if err := db.connected(); err != nil {
+		return Thread{}, err
+	}
+	output := Thread{}
+	if a != nil {
+		if _, err := db.byId(ctx, db.pool, threadId, a.MessageId); err != nil {
+			return output, err
+		}
+	}
+	var end Message
+	var err error
+	if b == nil {
+		end, err = db.latest(ctx, threadId)
+	} else {
+		end, err = db.byId(ctx, db.pool, threadId, b.MessageId)
+	}
+	if err != nil {
+		return output, err
+	}
+
+	// walk up the parents from the end, the path is returned root first
+	rows, err := db.pool.Query(
+		ctx,
+		`
+		WITH RECURSIVE path AS (
+			SELECT id, parent_id, 0 AS hops
+			FROM vriksham_messages
+			WHERE thread_id = $1 AND id = $2
+			UNION ALL
+			SELECT m.id, m.parent_id, p.hops + 1
+			FROM vriksham_messages m
+			JOIN path p ON m.thread_id = $1 AND m.id = p.parent_id
+		)
+		SELECT `+postgresMessageColumns+`
+		FROM path p
+		JOIN vriksham_messages m ON m.thread_id = $1 AND m.id = p.id
+		ORDER BY p.hops DESC
+		`,
+		threadId, end.MessageId,
+	)
+	if err != nil {
+		return output, err
+	}
+	defer rows.Close()
+	path := []Message{}
+	for rows.Next() {
+		m, err := postgresScanMessage(rows)
+		if err != nil {
+			return output, err
+		}
+		path = append(path, m)
+	}
+	if err := rows.Err(); err != nil {
+		return output, err
+	}
+
+	// the path always reaches the root, from a message the start has to be a proper ancestor of the end
+	start := 0
+	hops := len(path)
+	if a != nil {
+		start = -1
+		for i := 0; i < len(path)-1; i++ {
+			if path[i].MessageId == a.MessageId {
+				start = i
+				hops = len(path) - 1 - i
+			}
+		}
+		if start < 0 {
+			return output, nil
+		}
+	}
+	if hops > maxPickLength {
+		return output, pickTooLong()
+	}
+	output.Messages = path[start:]
+	return output, nil
+}
+
+func (db Backend_Postgres) SetLatestMessage(threadId string, latestMessage *Message, ctx context.Context) (Message, error) {
+	if err := db.connected(); err != nil {
+		return Message{}, err
+	}
+	output := Message{}
+	if latestMessage == nil {
+		return output, fmt.Errorf("%w: latest message cannot be empty", ErrInvalidMessage)
+	}
+	err := pgx.BeginFunc(ctx, db.pool, func(tx pgx.Tx) error {
+		m, err := db.byId(ctx, tx, threadId, latestMessage.MessageId)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(
+			ctx,
+			"UPDATE vriksham_messages SET latest = (id = $2) WHERE thread_id = $1 AND (latest OR id = $2)",
+			threadId, latestMessage.MessageId,
+		); err != nil {
+			return err
+		}
+		m.Latest = true
+		output = m
+		return nil
+	})
+	return output, err
+}
+
+func (db Backend_Postgres) Size(threadId string, ctx context.Context) (int, error) {
+	if err := db.connected(); err != nil {
+		return 0, err
+	}
+	return db.countInThread(ctx, threadId, "SELECT COUNT(*) FROM vriksham_messages WHERE thread_id = $1", threadId)
+}
+
+/*
+ListThreads reads the page of threads in the order of their primary key or of the vriksham_threads_activity index,
+then finds the depth of just those threads with one recursive walk and their size, oldest message and latest message
+in the same query. Every key of a metadata filter is compared with jsonb equality, so a filter {"tags": ["a"]} does
+not match {"tags": ["a", "b"]} like it would with containment.
+*/
+func (db Backend_Postgres) ListThreads(opts ListOptions, ctx context.Context) (ThreadPage, error) {
+	if err := db.connected(); err != nil {
+		return ThreadPage{}, err
+	}
+	q, err := newListQuery(opts)
+	if err != nil {
+		return ThreadPage{}, err
+	}
+	where, args := []string{}, []any{}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	switch {
+	case q.after == nil:
+	case q.OrderBy == ListByThreadId:
+		where = append(where, "t.thread_id > "+arg(q.after.ThreadId))
+	case q.after.UpdatedAt.IsZero():
+		where = append(where, "t.updated_at IS NULL AND t.thread_id > "+arg(q.after.ThreadId))
+	default:
+		updatedAt, threadId := arg(q.after.UpdatedAt), arg(q.after.ThreadId)
+		where = append(where, fmt.Sprintf(
+			"(t.updated_at < %s OR t.updated_at IS NULL OR (t.updated_at = %s AND t.thread_id > %s))",
+			updatedAt, updatedAt, threadId,
+		))
+	}
+	if len(q.Metadata) > 0 {
+		filter, err := metadataFilter(q.Metadata)
+		if err != nil {
+			return ThreadPage{}, err
+		}
+		match := "EXISTS (SELECT 1 FROM vriksham_messages f WHERE f.thread_id = t.thread_id"
+		for k, value := range filter {
+			match += fmt.Sprintf(" AND f.metadata -> %s::text = %s::jsonb", arg(k), arg(value))
+		}
+		where = append(where, match+")")
+	}
+	// the order for a table alias
+	order := "%[1]s.thread_id"
+	if q.OrderBy == ListByActivity {
+		order = "%[1]s.updated_at DESC NULLS LAST, %[1]s.thread_id"
+	}
+	page := "SELECT t.thread_id, t.updated_at FROM vriksham_threads t"
+	if len(where) > 0 {
+		page += " WHERE " + strings.Join(where, " AND ")
+	}
+	page += " ORDER BY " + fmt.Sprintf(order, "t") + " LIMIT " + arg(q.Limit+1)
+
+	rows, err := db.pool.Query(
+		ctx,
+		`
+		WITH RECURSIVE page AS (`+page+`),
+		tree AS (
+			SELECT m.thread_id, m.id, 1 AS depth
+			FROM vriksham_messages m
+			JOIN page p ON m.thread_id = p.thread_id
+			WHERE m.parent_id IS NULL
+			UNION ALL
+			SELECT m.thread_id, m.id, t.depth + 1
+			FROM vriksham_messages m
+			JOIN tree t ON m.thread_id = t.thread_id AND m.parent_id = t.id
+		)
+		SELECT `+postgresOptionalMessageColumns+`,
+			p.thread_id,
+			p.updated_at,
+			(SELECT COUNT(*) FROM vriksham_messages s WHERE s.thread_id = p.thread_id),
+			(SELECT COALESCE(MAX(d.depth), 0) FROM tree d WHERE d.thread_id = p.thread_id),
+			(SELECT MIN(s.created_at) FROM vriksham_messages s WHERE s.thread_id = p.thread_id)
+		FROM page p
+		LEFT JOIN vriksham_messages m ON m.thread_id = p.thread_id
+			AND m.seq = (SELECT MIN(l.seq) FROM vriksham_messages l WHERE l.thread_id = p.thread_id AND l.latest)
+		ORDER BY `+fmt.Sprintf(order, "p"),
+		args...,
+	)
+	if err != nil {
+		return ThreadPage{}, err
+	}
+	defer rows.Close()
+
+	threads := []ThreadSummary{}
+	for rows.Next() {
+		s := ThreadSummary{}
+		var updatedAt, createdAt *time.Time
+		latest, err := postgresScanMessage(rows, &s.ThreadId, &updatedAt, &s.Size, &s.Depth, &createdAt)
+		if err != nil {
+			return ThreadPage{}, err
+		}
+		if createdAt != nil {
+			s.CreatedAt = *createdAt
+		}
+		if updatedAt != nil {
+			s.UpdatedAt = *updatedAt
+		}
+		if latest.MessageId != "" {
+			s.Latest = &latest
+		}
+		threads = append(threads, s)
+	}
+	if err := rows.Err(); err != nil {
+		return ThreadPage{}, err
+	}
+	return q.page(threads), nil
+}
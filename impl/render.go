@@ -0,0 +1,157 @@
+package impl
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RenderOptions change what RenderTree prints, the zero value gives the plain tree shown above GetDemoTree
+type RenderOptions struct {
+	// MarkLatest adds " (latest)" after the latest message
+	MarkLatest bool
+
+	// ShowMetadata adds the role, author, creation time, rating, a snippet of the content and the metadata of every
+	// message
+	ShowMetadata bool
+
+	// CollapseChains folds runs of more than this many messages that each have a single child into one line that only
+	// shows the first and the last message, 0 never folds
+	CollapseChains int
+
+	// MaxDepth is the number of lines below the root that are shown, deeper messages are counted instead. 0 shows all
+	MaxDepth int
+}
+
+// maxSnippet is the number of characters of the content shown with ShowMetadata
+const maxSnippet = 40
+
+/*
+RenderTree draws a tree as returned by Get or GetChildren:
+
+	<ThreadRoot: tree_0000>
+	├── [msg_00]
+	│   ╰── [msg_06]
+	...
+
+Messages are listed in the order of the relations, a tree returned by GetChildren hangs below the message it started
+from.
+*/
+func RenderTree(tree ThreadTree, opts RenderOptions) string {
+	r := treeRenderer{opts: opts, messages: map[string]Message{}, children: map[string][]string{}}
+	hasParent := map[string]bool{}
+	for _, rel := range tree.Relations {
+		r.children[rel.StartId] = append(r.children[rel.StartId], rel.EndId)
+		hasParent[rel.EndId] = true
+	}
+	top := []string{}
+	for _, m := range tree.Messages {
+		r.messages[m.MessageId] = m
+		if !hasParent[m.MessageId] {
+			top = append(top, m.MessageId)
+		}
+	}
+	top = append(top, r.children[""]...)
+
+	r.b.WriteString(fmt.Sprintf("<ThreadRoot: %s>\n", tree.Root.ThreadId))
+	r.render(top, "", 1)
+	return r.b.String()
+}
+
+type treeRenderer struct {
+	opts     RenderOptions
+	messages map[string]Message
+	children map[string][]string
+	b        strings.Builder
+}
+
+func (r *treeRenderer) render(ids []string, prefix string, level int) {
+	for i, id := range ids {
+		branch, indent := "├── ", "│   "
+		if i == len(ids)-1 {
+			branch, indent = "╰── ", "    "
+		}
+		line, last := r.line(id)
+		r.b.WriteString(prefix + branch + line + "\n")
+
+		next := r.children[last]
+		if len(next) == 0 {
+			continue
+		}
+		if r.opts.MaxDepth > 0 && level >= r.opts.MaxDepth {
+			r.b.WriteString(fmt.Sprintf("%s╰── … %d more\n", prefix+indent, r.count(last)))
+			continue
+		}
+		r.render(next, prefix+indent, level+1)
+	}
+}
+
+// line is the text for `id`, with a folded chain it also returns the message at the end of the chain whose children
+// come next
+func (r *treeRenderer) line(id string) (string, string) {
+	if r.opts.CollapseChains <= 0 {
+		return r.label(id), id
+	}
+	chain := []string{id}
+	for {
+		last := chain[len(chain)-1]
+		next := r.children[last]
+		if len(next) != 1 || (len(chain) > 1 && r.opts.MarkLatest && r.messages[last].Latest) {
+			// the latest message is never folded away when it is marked
+			break
+		}
+		chain = append(chain, next[0])
+	}
+	if len(chain) <= r.opts.CollapseChains || len(chain) < 3 {
+		return r.label(id), id
+	}
+	last := chain[len(chain)-1]
+	return fmt.Sprintf("%s ┄┄ %d more ┄┄ %s", r.label(id), len(chain)-2, r.label(last)), last
+}
+
+func (r *treeRenderer) label(id string) string {
+	m := r.messages[id]
+	s := "[" + id + "]"
+	if r.opts.MarkLatest && m.Latest {
+		s += " (latest)"
+	}
+	if !r.opts.ShowMetadata {
+		return s
+	}
+	if m.Role != "" {
+		s += " role=" + m.Role
+	}
+	if m.Author != "" {
+		s += " author=" + m.Author
+	}
+	if !m.CreatedAt.IsZero() {
+		s += " created_at=" + m.CreatedAt.Format(time.RFC3339)
+	}
+	if m.Rating != nil {
+		s += fmt.Sprintf(" thumbs=%+d score=%g", m.Rating.Thumbs, m.Rating.Score)
+	}
+	if m.Content != "" {
+		content := []rune(m.Content)
+		snippet := string(content)
+		if len(content) > maxSnippet {
+			snippet = string(content[:maxSnippet]) + "…"
+		}
+		s += fmt.Sprintf(" content=%q", snippet)
+	}
+	if len(m.Metadata) > 0 {
+		if metadata, err := json.Marshal(m.Metadata); err == nil {
+			s += " metadata=" + string(metadata)
+		}
+	}
+	return s
+}
+
+// count is the number of messages below `id`
+func (r *treeRenderer) count(id string) int {
+	n := 0
+	for _, c := range r.children[id] {
+		n += 1 + r.count(c)
+	}
+	return n
+}
@@ -0,0 +1,140 @@
+package impl_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	Impl "github.com/yashbonde/vriksham/impl"
+)
+
+// the drawing in the doc comment of GetDemoTree
+const demoDrawing = `<ThreadRoot: tree_0000>
+├── [msg_00]
+│   ╰── [msg_06]
+│       ├── [msg_14]
+│       │   ╰── [msg_15]
+│       │       ╰── [msg_22]
+│       │           ╰── [msg_23]
+│       │               ├── [msg_24]
+│       │               │   ╰── [msg_25]
+│       │               ╰── [msg_26]
+│       │                   ╰── [msg_27]
+│       ╰── [msg_16]
+│           ╰── [msg_17]
+│               ├── [msg_18]
+│               │   ╰── [msg_19]
+│               ╰── [msg_20]
+│                   ╰── [msg_21]
+├── [msg_01]
+│   ╰── [msg_07]
+├── [msg_02]
+│   ╰── [msg_08]
+├── [msg_03]
+│   ╰── [msg_09]
+├── [msg_04]
+│   ╰── [msg_10]
+╰── [msg_05]
+    ╰── [msg_11]
+        ╰── [msg_12]
+            ╰── [msg_13]
+`
+
+func expectDrawing(t *testing.T, name, got, want string) {
+	t.Helper()
+	if got != want {
+		t.Errorf("%s: got\n%s\nwant\n%s", name, got, want)
+	}
+}
+
+func TestRenderTree(t *testing.T) {
+	demo := Impl.GetDemoTree()
+	expectDrawing(t, "RenderTree", Impl.RenderTree(*demo, Impl.RenderOptions{}), demoDrawing)
+
+	latest := Impl.RenderTree(*demo, Impl.RenderOptions{MarkLatest: true})
+	expectDrawing(t, "RenderTree(MarkLatest)", latest,
+		strings.Replace(demoDrawing, "[msg_27]", "[msg_27] (latest)", 1))
+}
+
+func TestRenderTreeSubtree(t *testing.T) {
+	// what GetChildren(msg_17, 2) returns
+	tree := Impl.ThreadTree{
+		Root:     Impl.ThreadRoot{ThreadId: "tree_0000"},
+		Messages: []Impl.Message{{MessageId: "msg_17"}, {MessageId: "msg_18"}, {MessageId: "msg_20"}},
+		Relations: []Impl.Triple{
+			{StartId: "msg_17", Relation: "CHILD", EndId: "msg_18"},
+			{StartId: "msg_17", Relation: "CHILD", EndId: "msg_20"},
+		},
+	}
+	expectDrawing(t, "RenderTree(msg_17)", Impl.RenderTree(tree, Impl.RenderOptions{}), `<ThreadRoot: tree_0000>
+╰── [msg_17]
+    ├── [msg_18]
+    ╰── [msg_20]
+`)
+}
+
+func TestRenderTreeOptions(t *testing.T) {
+	demo := Impl.GetDemoTree()
+	got := Impl.RenderTree(*demo, Impl.RenderOptions{MaxDepth: 2})
+	expectDrawing(t, "RenderTree(MaxDepth)", got, `<ThreadRoot: tree_0000>
+├── [msg_00]
+│   ╰── [msg_06]
+│       ╰── … 14 more
+├── [msg_01]
+│   ╰── [msg_07]
+├── [msg_02]
+│   ╰── [msg_08]
+├── [msg_03]
+│   ╰── [msg_09]
+├── [msg_04]
+│   ╰── [msg_10]
+╰── [msg_05]
+    ╰── [msg_11]
+        ╰── … 2 more
+`)
+
+	// msg_26 -> msg_27 is not folded since msg_27 is marked as the latest message
+	got = Impl.RenderTree(*demo, Impl.RenderOptions{CollapseChains: 3, MarkLatest: true})
+	expectDrawing(t, "RenderTree(CollapseChains)", got, `<ThreadRoot: tree_0000>
+├── [msg_00]
+│   ╰── [msg_06]
+│       ├── [msg_14] ┄┄ 2 more ┄┄ [msg_23]
+│       │   ├── [msg_24]
+│       │   │   ╰── [msg_25]
+│       │   ╰── [msg_26]
+│       │       ╰── [msg_27] (latest)
+│       ╰── [msg_16]
+│           ╰── [msg_17]
+│               ├── [msg_18]
+│               │   ╰── [msg_19]
+│               ╰── [msg_20]
+│                   ╰── [msg_21]
+├── [msg_01]
+│   ╰── [msg_07]
+├── [msg_02]
+│   ╰── [msg_08]
+├── [msg_03]
+│   ╰── [msg_09]
+├── [msg_04]
+│   ╰── [msg_10]
+╰── [msg_05] ┄┄ 2 more ┄┄ [msg_13]
+`)
+
+	tree := Impl.ThreadTree{
+		Root: Impl.ThreadRoot{ThreadId: "rich_thread"},
+		Messages: []Impl.Message{{
+			MessageId: "rich_00",
+			Role:      "user",
+			Author:    "yash",
+			CreatedAt: time.Date(2024, 3, 1, 12, 30, 15, 0, time.UTC),
+			Content:   "a question that is long enough to be cut short in the drawing",
+			Metadata:  map[string]any{"model": "gpt-4o"},
+			Rating:    &Impl.Rating{Thumbs: 1, Score: 0.5},
+		}},
+		Relations: []Impl.Triple{{Relation: "CHILD", EndId: "rich_00"}},
+	}
+	got = Impl.RenderTree(tree, Impl.RenderOptions{ShowMetadata: true})
+	expectDrawing(t, "RenderTree(ShowMetadata)", got, `<ThreadRoot: rich_thread>
+╰── [rich_00] role=user author=yash created_at=2024-03-01T12:30:15Z thumbs=+1 score=0.5 content="a question that is long enough to be cut…" metadata={"model":"gpt-4o"}
+`)
+}
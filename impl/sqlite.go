@@ -0,0 +1,815 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+/*
+Backend_SQLite stores every thread in a single SQLite file. Along with the `messages` table it keeps a closure table
+that has one row for every (ancestor, descendant) pair in a thread, so the tree questions are answered with indexed
+lookups instead of recursive scans. The ThreadRoot is the node with the empty id, for example msg_06 of the demo tree
+has the rows:
+
+	ancestor | descendant | depth
+	---------+------------+------
+	         | msg_06     | 2
+	msg_00   | msg_06     | 1
+	msg_06   | msg_06     | 0
+
+The schema is created and migrated by Connect.
+*/
+type Backend_SQLite struct {
+	Path string `json:"path"`
+	db   *sql.DB
+}
+
+// sqliteMigrations are applied in order, `PRAGMA user_version` stores how many have been applied
+var sqliteMigrations = []string{
+	`
+	CREATE TABLE threads (
+		thread_id TEXT PRIMARY KEY
+	);
+	CREATE TABLE messages (
+		seq       INTEGER PRIMARY KEY AUTOINCREMENT,
+		thread_id TEXT NOT NULL,
+		id        TEXT NOT NULL,
+		parent_id TEXT,
+		latest    INTEGER NOT NULL DEFAULT 0,
+		UNIQUE (thread_id, id)
+	);
+	CREATE INDEX messages_parent ON messages (thread_id, parent_id);
+	CREATE INDEX messages_latest ON messages (thread_id, latest);
+	CREATE TABLE closure (
+		thread_id  TEXT NOT NULL,
+		ancestor   TEXT NOT NULL,
+		descendant TEXT NOT NULL,
+		depth      INTEGER NOT NULL,
+		PRIMARY KEY (thread_id, ancestor, descendant)
+	);
+	CREATE INDEX closure_descendant ON closure (thread_id, descendant, depth);
+	CREATE INDEX closure_ancestor_depth ON closure (thread_id, ancestor, depth);
+	`,
+	`
+	ALTER TABLE messages ADD COLUMN role TEXT NOT NULL DEFAULT '';
+	ALTER TABLE messages ADD COLUMN content TEXT NOT NULL DEFAULT '';
+	ALTER TABLE messages ADD COLUMN author TEXT NOT NULL DEFAULT '';
+	ALTER TABLE messages ADD COLUMN created_at TEXT;
+	ALTER TABLE messages ADD COLUMN metadata TEXT;
+	`,
+	`
+	ALTER TABLE messages ADD COLUMN thumbs INTEGER;
+	ALTER TABLE messages ADD COLUMN score REAL;
+	`,
+	// the julianday of the newest created_at of every thread, ListThreads orders by it
+	`
+	ALTER TABLE threads ADD COLUMN activity REAL;
+	UPDATE threads SET activity = (SELECT MAX(julianday(m.created_at)) FROM messages m WHERE m.thread_id = threads.thread_id);
+	CREATE INDEX threads_activity ON threads (activity DESC, thread_id);
+	CREATE TRIGGER messages_activity_insert AFTER INSERT ON messages WHEN NEW.created_at IS NOT NULL
+	BEGIN
+		UPDATE threads SET activity = julianday(NEW.created_at)
+		WHERE thread_id = NEW.thread_id AND (activity IS NULL OR activity < julianday(NEW.created_at));
+	END;
+	CREATE TRIGGER messages_activity_delete AFTER DELETE ON messages WHEN OLD.created_at IS NOT NULL
+	BEGIN
+		UPDATE threads
+		SET activity = (SELECT MAX(julianday(m.created_at)) FROM messages m WHERE m.thread_id = OLD.thread_id)
+		WHERE thread_id = OLD.thread_id AND activity <= julianday(OLD.created_at);
+	END;
+	`,
+}
+
+// sqliteMessageColumns are the columns read by sqliteScanMessage, the messages table is always aliased as `m`
+const sqliteMessageColumns = "m.id, m.latest, m.role, m.content, m.author, m.created_at, m.metadata, m.thumbs, m.score"
+
+// sqliteOptionalMessageColumns are sqliteMessageColumns for a left joined `m`, no message is read with an empty id
+const sqliteOptionalMessageColumns = "COALESCE(m.id, ''), COALESCE(m.latest, 0), COALESCE(m.role, ''), " +
+	"COALESCE(m.content, ''), COALESCE(m.author, ''), m.created_at, m.metadata, m.thumbs, m.score"
+
+// sqliteScanMessage reads the sqliteMessageColumns followed by the `extra` columns
+func sqliteScanMessage(row interface{ Scan(...any) error }, extra ...any) (Message, error) {
+	m := Message{}
+	var createdAt, metadata sql.NullString
+	var thumbs sql.NullInt64
+	var score sql.NullFloat64
+	dest := append([]any{&m.MessageId, &m.Latest, &m.Role, &m.Content, &m.Author, &createdAt, &metadata, &thumbs, &score}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return m, err
+	}
+	if createdAt.Valid {
+		t, err := time.Parse(time.RFC3339Nano, createdAt.String)
+		if err != nil {
+			return m, err
+		}
+		m.CreatedAt = t
+	}
+	if metadata.Valid {
+		data, err := decodeMetadata(metadata.String)
+		if err != nil {
+			return m, err
+		}
+		m.Metadata = data
+	}
+	if thumbs.Valid {
+		m.Rating = &Rating{Thumbs: int(thumbs.Int64), Score: score.Float64}
+	}
+	return m, nil
+}
+
+// sqlitePayload returns the role, content, author, created_at, metadata, thumbs and score values to store for `m`
+func sqlitePayload(m Message) ([]any, error) {
+	var createdAt, metadata, thumbs, score any
+	if !m.CreatedAt.IsZero() {
+		createdAt = m.CreatedAt.Format(time.RFC3339Nano)
+	}
+	if len(m.Metadata) > 0 {
+		data, err := encodeMetadata(m.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		metadata = data
+	}
+	if m.Rating != nil {
+		thumbs, score = m.Rating.Thumbs, m.Rating.Score
+	}
+	return []any{m.Role, m.Content, m.Author, createdAt, metadata, thumbs, score}, nil
+}
+
+func init() {
+	Register("sqlite", func(ctx context.Context, u *url.URL) (TreeEngine, error) {
+		path, err := filePath(u)
+		if err != nil {
+			return nil, err
+		}
+		backend := &Backend_SQLite{Path: path}
+		return backend, backend.Connect(ctx)
+	})
+}
+
+func (backend *Backend_SQLite) Connect(ctx context.Context) error {
+	db, err := sql.Open("sqlite", backend.Path+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return err
+	}
+	// a single connection keeps writers from tripping over each other and makes ":memory:" databases work
+	db.SetMaxOpenConns(1)
+	if _, _, err := sqliteMigrate(ctx, db); err != nil {
+		db.Close()
+		return err
+	}
+	backend.db = db
+	return nil
+}
+
+func (backend *Backend_SQLite) Close() error {
+	if backend.db == nil {
+		return nil
+	}
+	err := backend.db.Close()
+	backend.db = nil
+	return err
+}
+
+// connected is ErrNotConnected before Connect and after Close, every method checks it before using the database
+func (db Backend_SQLite) connected() error {
+	if db.db == nil {
+		return ErrNotConnected
+	}
+	return nil
+}
+
+func (db Backend_SQLite) Ping(ctx context.Context) error {
+	if err := db.connected(); err != nil {
+		return err
+	}
+	return db.db.PingContext(ctx)
+}
+
+// Info counts a file as shared since other processes wait on the busy timeout for their turn, ":memory:" is neither
+// shared nor persistent
+func (db Backend_SQLite) Info(ctx context.Context) (BackendInfo, error) {
+	info := BackendInfo{Name: "sqlite", Capabilities: []string{}}
+	if db.Path != ":memory:" {
+		info.Capabilities = append(info.Capabilities, CapabilityPersistent, CapabilityShared)
+	}
+	if err := db.connected(); err != nil {
+		return info, err
+	}
+	err := db.db.QueryRowContext(ctx, "SELECT sqlite_version()").Scan(&info.Version)
+	return info, err
+}
+
+func sqliteMigrate(ctx context.Context, db *sql.DB) (int, int, error) {
+	version := 0
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return 0, 0, err
+	}
+	for i := version; i < len(sqliteMigrations); i++ {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return version, i, err
+		}
+		if _, err := tx.ExecContext(ctx, sqliteMigrations[i]); err != nil {
+			tx.Rollback()
+			return version, i, fmt.Errorf("migration %d: %w", i+1, err)
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return version, i, err
+		}
+		if err := tx.Commit(); err != nil {
+			return version, i, err
+		}
+	}
+	return version, max(version, len(sqliteMigrations)), nil
+}
+
+func (db Backend_SQLite) Migrate(ctx context.Context) (int, int, error) {
+	if err := db.connected(); err != nil {
+		return 0, 0, err
+	}
+	return sqliteMigrate(ctx, db.db)
+}
+
+func (db Backend_SQLite) transaction(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (db Backend_SQLite) count(ctx context.Context, query string, args ...any) (int, error) {
+	output := 0
+	err := db.db.QueryRowContext(ctx, query, args...).Scan(&output)
+	return output, err
+}
+
+// subtree returns the messages below `startId` (upto `levels` deep, -1 for no limit) along with their relations
+func (db Backend_SQLite) subtree(ctx context.Context, threadId, startId string, levels int) ([]Message, []Triple, error) {
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT `+sqliteMessageColumns+`, m.parent_id
+		FROM closure c
+		JOIN messages m ON m.thread_id = c.thread_id AND m.id = c.descendant
+		WHERE c.thread_id = ? AND c.ancestor = ? AND c.depth > 0 AND (? < 0 OR c.depth <= ?)
+		ORDER BY c.depth, m.seq
+		`,
+		threadId, startId, levels, levels,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	messages := []Message{}
+	relations := []Triple{}
+	for rows.Next() {
+		parentId := ""
+		m, err := sqliteScanMessage(rows, &parentId)
+		if err != nil {
+			return nil, nil, err
+		}
+		messages = append(messages, m)
+		relations = append(relations, Triple{StartId: parentId, Relation: "CHILD", EndId: m.MessageId})
+	}
+	return messages, relations, rows.Err()
+}
+
+// sqliteQuerier is either the database or an open transaction
+type sqliteQuerier interface {
+	QueryRowContext(context.Context, string, ...any) *sql.Row
+}
+
+func (db Backend_SQLite) message(ctx context.Context, q sqliteQuerier, threadId, where string, args ...any) (Message, error) {
+	return sqliteScanMessage(q.QueryRowContext(
+		ctx,
+		"SELECT "+sqliteMessageColumns+" FROM messages m WHERE m.thread_id = ? AND "+where+" ORDER BY m.seq LIMIT 1",
+		append([]any{threadId}, args...)...,
+	))
+}
+
+// sqliteInsertChild adds the message and its closure rows, the parent must already be in the closure table
+func sqliteInsertChild(ctx context.Context, tx *sql.Tx, threadId string, m Message, parentId string) error {
+	payload, err := sqlitePayload(m)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO messages (thread_id, id, parent_id, latest, role, content, author, created_at, metadata, thumbs, score)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		append([]any{threadId, m.MessageId, parentId, m.Latest}, payload...)...,
+	); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		`
+		INSERT INTO closure (thread_id, ancestor, descendant, depth)
+		SELECT thread_id, ancestor, ?, depth + 1 FROM closure WHERE thread_id = ? AND descendant = ?
+		UNION ALL SELECT ?, ?, ?, 0
+		`,
+		m.MessageId, threadId, parentId,
+		threadId, m.MessageId, m.MessageId,
+	)
+	return err
+}
+
+// checkThread returns ErrThreadNotFound when the thread is not stored
+func (db Backend_SQLite) checkThread(ctx context.Context, q sqliteQuerier, threadId string) error {
+	found := 0
+	if err := q.QueryRowContext(ctx, "SELECT COUNT(*) FROM threads WHERE thread_id = ?", threadId).Scan(&found); err != nil {
+		return err
+	} else if found == 0 {
+		return threadNotFound(threadId)
+	}
+	return nil
+}
+
+// byId returns the message `id`, telling apart a missing thread from a missing message
+func (db Backend_SQLite) byId(ctx context.Context, q sqliteQuerier, threadId, id string) (Message, error) {
+	m, err := db.message(ctx, q, threadId, "m.id = ?", id)
+	if err == sql.ErrNoRows {
+		if err := db.checkThread(ctx, q, threadId); err != nil {
+			return m, err
+		}
+		return m, messageNotFound(threadId, id)
+	}
+	return m, err
+}
+
+// countInThread runs a counting query, a zero count is checked against a missing thread
+func (db Backend_SQLite) countInThread(ctx context.Context, threadId, query string, args ...any) (int, error) {
+	output, err := db.count(ctx, query, args...)
+	if err == nil && output == 0 {
+		err = db.checkThread(ctx, db.db, threadId)
+	}
+	return output, err
+}
+
+// implement interface
+
+func (db Backend_SQLite) AddMessage(threadId string, a, b *Message, ctx context.Context) error {
+	if err := db.connected(); err != nil {
+		return err
+	}
+	if a == nil || a.MessageId == "" {
+		return fmt.Errorf("%w: message to be inserted cannot be empty", ErrInvalidMessage)
+	}
+	return db.transaction(ctx, func(tx *sql.Tx) error {
+		parentId := ""
+		if b != nil {
+			if _, err := db.byId(ctx, tx, threadId, b.MessageId); err != nil {
+				return err
+			}
+			parentId = b.MessageId
+		} else if err := db.checkThread(ctx, tx, threadId); err != nil {
+			return err
+		}
+		if _, err := db.message(ctx, tx, threadId, "m.id = ?", a.MessageId); err == nil {
+			return duplicateMessage(threadId, a.MessageId)
+		} else if err != sql.ErrNoRows {
+			return err
+		}
+		// the latest message is only changed through SetLatestMessage
+		payload := *a
+		payload.Latest = false
+		return sqliteInsertChild(ctx, tx, threadId, payload, parentId)
+	})
+}
+
+func (db Backend_SQLite) AddTree(threadId string, tree ThreadTree, ctx context.Context) error {
+	if err := db.connected(); err != nil {
+		return err
+	}
+	if err := validateTree(threadId, tree); err != nil {
+		return err
+	}
+
+	return db.transaction(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO threads (thread_id) VALUES (?) ON CONFLICT DO NOTHING", threadId); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(
+			ctx,
+			"INSERT INTO closure (thread_id, ancestor, descendant, depth) VALUES (?, '', '', 0) ON CONFLICT DO NOTHING",
+			threadId,
+		); err != nil {
+			return err
+		}
+
+		// load what already exists so merging a tree behaves the same as the other backends
+		existing := map[string]string{}
+		rows, err := tx.QueryContext(ctx, "SELECT id, parent_id FROM messages WHERE thread_id = ?", threadId)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			id, parentId := "", ""
+			if err := rows.Scan(&id, &parentId); err != nil {
+				rows.Close()
+				return err
+			}
+			existing[id] = parentId
+		}
+		rows.Close()
+
+		messages := map[string]Message{}
+		for _, m := range tree.Messages {
+			messages[m.MessageId] = m
+		}
+		pending := map[string][]Triple{}
+		for _, r := range tree.Relations {
+			_, inTree := messages[r.StartId]
+			_, stored := existing[r.StartId]
+			if r.StartId != "" && !inTree && !stored {
+				return invalidTree("relation starts from unknown message %s", r.StartId)
+			}
+			_, inTree = messages[r.EndId]
+			p, stored := existing[r.EndId]
+			if !inTree && !stored {
+				return invalidTree("relation ends at unknown message %s", r.EndId)
+			} else if stored && p != r.StartId {
+				return invalidTree("message %s already has a parent", r.EndId)
+			} else if stored {
+				continue
+			}
+			pending[r.StartId] = append(pending[r.StartId], r)
+		}
+
+		// insert top down so the parent closure rows always exist
+		frontier := []string{""}
+		for id := range existing {
+			frontier = append(frontier, id)
+		}
+		for len(frontier) > 0 {
+			next := []string{}
+			for _, parentId := range frontier {
+				for _, r := range pending[parentId] {
+					if err := sqliteInsertChild(ctx, tx, threadId, messages[r.EndId], parentId); err != nil {
+						return err
+					}
+					next = append(next, r.EndId)
+				}
+			}
+			frontier = next
+		}
+		return nil
+	})
+}
+
+func (db Backend_SQLite) Breadth(threadId string, ctx context.Context) (int, error) {
+	if err := db.connected(); err != nil {
+		return 0, err
+	}
+	return db.countInThread(
+		ctx,
+		threadId,
+		`
+		SELECT COUNT(*) FROM closure c
+		WHERE c.thread_id = ? AND c.ancestor = '' AND c.depth > 0
+		AND NOT EXISTS (SELECT 1 FROM messages m WHERE m.thread_id = c.thread_id AND m.parent_id = c.descendant)
+		`,
+		threadId,
+	)
+}
+
+func (db Backend_SQLite) Degree(threadId string, message *Message, ctx context.Context) (int, error) {
+	if err := db.connected(); err != nil {
+		return 0, err
+	}
+	startId := ""
+	if message != nil {
+		if _, err := db.byId(ctx, db.db, threadId, message.MessageId); err != nil {
+			return 0, err
+		}
+		startId = message.MessageId
+	}
+	return db.countInThread(
+		ctx,
+		threadId,
+		"SELECT COUNT(*) FROM messages WHERE thread_id = ? AND parent_id = ?",
+		threadId, startId,
+	)
+}
+
+func (db Backend_SQLite) Delete(threadId string, message *Message, ctx context.Context) error {
+	if err := db.connected(); err != nil {
+		return err
+	}
+	return db.transaction(ctx, func(tx *sql.Tx) error {
+		if message == nil {
+			if err := db.checkThread(ctx, tx, threadId); err != nil {
+				return err
+			}
+			// the thread goes first so the messages_activity_delete trigger has nothing to update
+			for _, table := range []string{"threads", "closure", "messages"} {
+				if _, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE thread_id = ?", threadId); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		if _, err := db.byId(ctx, tx, threadId, message.MessageId); err != nil {
+			return err
+		}
+		subtree := "SELECT descendant FROM closure WHERE thread_id = ? AND ancestor = ?"
+		if _, err := tx.ExecContext(
+			ctx,
+			"DELETE FROM messages WHERE thread_id = ? AND id IN ("+subtree+")",
+			threadId, threadId, message.MessageId,
+		); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(
+			ctx,
+			"DELETE FROM closure WHERE thread_id = ? AND descendant IN ("+subtree+")",
+			threadId, threadId, message.MessageId,
+		)
+		return err
+	})
+}
+
+func (db Backend_SQLite) Depth(threadId string, ctx context.Context) (int, error) {
+	if err := db.connected(); err != nil {
+		return 0, err
+	}
+	return db.countInThread(
+		ctx,
+		threadId,
+		"SELECT COALESCE(MAX(depth), 0) FROM closure WHERE thread_id = ? AND ancestor = ''",
+		threadId,
+	)
+}
+
+func (db Backend_SQLite) Get(threadId string, ctx context.Context) (ThreadTree, error) {
+	if err := db.connected(); err != nil {
+		return ThreadTree{}, err
+	}
+	output := ThreadTree{}
+	messages, relations, err := db.subtree(ctx, threadId, "", -1)
+	if err != nil {
+		return output, err
+	} else if len(messages) == 0 {
+		if err := db.checkThread(ctx, db.db, threadId); err != nil {
+			return output, err
+		}
+	}
+	output.Root = ThreadRoot{ThreadId: threadId}
+	output.Messages = messages
+	output.Relations = relations
+	return output, nil
+}
+
+func (db Backend_SQLite) GetChildren(threadId string, message *Message, depth int, ctx context.Context) (ThreadTree, error) {
+	if err := db.connected(); err != nil {
+		return ThreadTree{}, err
+	}
+	output := ThreadTree{}
+	if err := validateDepth(depth); err != nil {
+		return output, err
+	} else if depth == 1 {
+		depth = 2
+	}
+	startId := ""
+	if message != nil {
+		m, err := db.byId(ctx, db.db, threadId, message.MessageId)
+		if err != nil {
+			return output, err
+		}
+		startId = m.MessageId
+		output.Messages = append(output.Messages, m)
+	} else if err := db.checkThread(ctx, db.db, threadId); err != nil {
+		return output, err
+	}
+	messages, relations, err := db.subtree(ctx, threadId, startId, depth-1)
+	if err != nil {
+		return ThreadTree{}, err
+	}
+	output.Root = ThreadRoot{ThreadId: threadId}
+	output.Messages = append(output.Messages, messages...)
+	output.Relations = relations
+	return output, nil
+}
+
+// latest returns the latest message, telling apart a missing thread from a thread without a latest message
+func (db Backend_SQLite) latest(ctx context.Context, threadId string) (Message, error) {
+	output, err := db.message(ctx, db.db, threadId, "m.latest = 1")
+	if err == sql.ErrNoRows {
+		if err := db.checkThread(ctx, db.db, threadId); err != nil {
+			return output, err
+		}
+		return output, ErrNoLatest
+	}
+	return output, err
+}
+
+func (db Backend_SQLite) GetLatestMessage(threadId string, ctx context.Context) (Message, error) {
+	if err := db.connected(); err != nil {
+		return Message{}, err
+	}
+	return db.latest(ctx, threadId)
+}
+
+func (db Backend_SQLite) Pick(threadId string, a *Message, b *Message, ctx context.Context) (Thread, error) {
+	if err := db.connected(); err != nil {
+		return Thread{}, err
+	}
+	output := Thread{}
+	startId := ""
+	if a != nil {
+		if _, err := db.byId(ctx, db.db, threadId, a.MessageId); err != nil {
+			return output, err
+		}
+		startId = a.MessageId
+	}
+	var end Message
+	var err error
+	if b == nil {
+		end, err = db.latest(ctx, threadId)
+	} else {
+		end, err = db.byId(ctx, db.db, threadId, b.MessageId)
+	}
+	if err != nil {
+		return output, err
+	}
+
+	// the closure row between the two ends tells if there is a path and how long it is
+	length := 0
+	err = db.db.QueryRowContext(
+		ctx,
+		"SELECT depth FROM closure WHERE thread_id = ? AND ancestor = ? AND descendant = ?",
+		threadId, startId, end.MessageId,
+	).Scan(&length)
+	if err == sql.ErrNoRows || length < 1 {
+		return output, nil
+	} else if err != nil {
+		return output, err
+	} else if length > maxPickLength {
+		return output, pickTooLong()
+	}
+
+	rows, err := db.db.QueryContext(
+		ctx,
+		`
+		SELECT `+sqliteMessageColumns+`
+		FROM closure c
+		JOIN messages m ON m.thread_id = c.thread_id AND m.id = c.ancestor
+		WHERE c.thread_id = ? AND c.descendant = ? AND c.depth <= ?
+		ORDER BY c.depth DESC
+		`,
+		threadId, end.MessageId, length,
+	)
+	if err != nil {
+		return output, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		m, err := sqliteScanMessage(rows)
+		if err != nil {
+			return output, err
+		}
+		output.Messages = append(output.Messages, m)
+	}
+	return output, rows.Err()
+}
+
+func (db Backend_SQLite) SetLatestMessage(threadId string, latestMessage *Message, ctx context.Context) (Message, error) {
+	if err := db.connected(); err != nil {
+		return Message{}, err
+	}
+	output := Message{}
+	if latestMessage == nil {
+		return output, fmt.Errorf("%w: latest message cannot be empty", ErrInvalidMessage)
+	}
+	err := db.transaction(ctx, func(tx *sql.Tx) error {
+		m, err := db.byId(ctx, tx, threadId, latestMessage.MessageId)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(
+			ctx,
+			"UPDATE messages SET latest = (id = ?) WHERE thread_id = ?",
+			latestMessage.MessageId, threadId,
+		); err != nil {
+			return err
+		}
+		m.Latest = true
+		output = m
+		return nil
+	})
+	return output, err
+}
+
+func (db Backend_SQLite) Size(threadId string, ctx context.Context) (int, error) {
+	if err := db.connected(); err != nil {
+		return 0, err
+	}
+	return db.countInThread(
+		ctx,
+		threadId,
+		"SELECT COUNT(*) FROM closure WHERE thread_id = ? AND ancestor = '' AND depth > 0",
+		threadId,
+	)
+}
+
+/*
+ListThreads walks the threads in the order of their primary key or of the threads_activity index and stops after the
+page, the size, depth, times and latest message of those threads come from subqueries and a join in the same query.
+The times are found with julianday since the stored RFC 3339 strings can be in any time zone, the cursor of the
+activity order is compared the same way. A metadata filter is matched against the keys of json_each with their JSON
+type, so true is not 1.
+*/
+func (db Backend_SQLite) ListThreads(opts ListOptions, ctx context.Context) (ThreadPage, error) {
+	if err := db.connected(); err != nil {
+		return ThreadPage{}, err
+	}
+	q, err := newListQuery(opts)
+	if err != nil {
+		return ThreadPage{}, err
+	}
+	where, args := []string{}, []any{}
+	switch {
+	case q.after == nil:
+	case q.OrderBy == ListByThreadId:
+		where = append(where, "t.thread_id > ?")
+		args = append(args, q.after.ThreadId)
+	case q.after.UpdatedAt.IsZero():
+		where = append(where, "t.activity IS NULL AND t.thread_id > ?")
+		args = append(args, q.after.ThreadId)
+	default:
+		updatedAt := q.after.UpdatedAt.Format(time.RFC3339Nano)
+		where = append(where, "(t.activity < julianday(?) OR t.activity IS NULL OR (t.activity = julianday(?) AND t.thread_id > ?))")
+		args = append(args, updatedAt, updatedAt, q.after.ThreadId)
+	}
+	if len(q.Metadata) > 0 {
+		filter, err := metadataFilter(q.Metadata)
+		if err != nil {
+			return ThreadPage{}, err
+		}
+		match := "EXISTS (SELECT 1 FROM messages f WHERE f.thread_id = t.thread_id AND f.metadata IS NOT NULL"
+		for k, value := range filter {
+			match += " AND EXISTS (SELECT 1 FROM json_each(f.metadata) j" +
+				" WHERE j.key = ? AND j.type = json_type(?) AND j.value IS json_extract(?, '$'))"
+			args = append(args, k, value, value)
+		}
+		where = append(where, match+")")
+	}
+	query := `
+		SELECT ` + sqliteOptionalMessageColumns + `,
+			t.thread_id,
+			(SELECT COUNT(*) FROM closure c WHERE c.thread_id = t.thread_id AND c.ancestor = '' AND c.depth > 0),
+			(SELECT COALESCE(MAX(c.depth), 0) FROM closure c WHERE c.thread_id = t.thread_id AND c.ancestor = ''),
+			(SELECT s.created_at FROM messages s WHERE s.thread_id = t.thread_id AND s.created_at IS NOT NULL
+				ORDER BY julianday(s.created_at) LIMIT 1),
+			(SELECT s.created_at FROM messages s WHERE s.thread_id = t.thread_id AND s.created_at IS NOT NULL
+				ORDER BY julianday(s.created_at) DESC LIMIT 1)
+		FROM threads t
+		LEFT JOIN messages m ON m.thread_id = t.thread_id
+			AND m.seq = (SELECT MIN(l.seq) FROM messages l WHERE l.thread_id = t.thread_id AND l.latest = 1)
+		`
+	if len(where) > 0 {
+		query += "WHERE " + strings.Join(where, " AND ") + "\n"
+	}
+	if q.OrderBy == ListByActivity {
+		query += "ORDER BY t.activity DESC, t.thread_id LIMIT ?"
+	} else {
+		query += "ORDER BY t.thread_id LIMIT ?"
+	}
+	rows, err := db.db.QueryContext(ctx, query, append(args, q.Limit+1)...)
+	if err != nil {
+		return ThreadPage{}, err
+	}
+	defer rows.Close()
+
+	threads := []ThreadSummary{}
+	for rows.Next() {
+		s := ThreadSummary{}
+		var createdAt, updatedAt sql.NullString
+		latest, err := sqliteScanMessage(rows, &s.ThreadId, &s.Size, &s.Depth, &createdAt, &updatedAt)
+		if err != nil {
+			return ThreadPage{}, err
+		}
+		s.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt.String)
+		s.UpdatedAt, _ = time.Parse(time.RFC3339Nano, updatedAt.String)
+		if latest.MessageId != "" {
+			s.Latest = &latest
+		}
+		threads = append(threads, s)
+	}
+	if err := rows.Err(); err != nil {
+		return ThreadPage{}, err
+	}
+	return q.page(threads), nil
+}
@@ -0,0 +1,37 @@
+package impl_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	Impl "github.com/yashbonde/vriksham/impl"
+	"github.com/yashbonde/vriksham/impl/enginetest"
+)
+
+func TestBackendSQLite(t *testing.T) {
+	enginetest.Run(t, func(t *testing.T) Impl.TreeEngine {
+		backend := &Impl.Backend_SQLite{Path: filepath.Join(t.TempDir(), "vriksham.db")}
+		if err := backend.Connect(context.Background()); err != nil {
+			t.Fatalf("Connect: %v", err)
+		}
+		return backend
+	})
+}
+
+func TestMigrateSQLite(t *testing.T) {
+	ctx := context.Background()
+	backend := &Impl.Backend_SQLite{Path: filepath.Join(t.TempDir(), "vriksham.db")}
+	if _, _, err := backend.Migrate(ctx); !errors.Is(err, Impl.ErrNotConnected) {
+		t.Errorf("Migrate before Connect: got %v, want ErrNotConnected", err)
+	}
+	if err := backend.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer backend.Close()
+	// Connect already migrated
+	if from, to, err := backend.Migrate(ctx); err != nil || from != 4 || to != 4 {
+		t.Errorf("Migrate: got %d to %d, %v, want 4 to 4", from, to, err)
+	}
+}
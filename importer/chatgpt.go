@@ -0,0 +1,219 @@
+/*
+Package importer loads conversations exported from other tools into a TreeEngine.
+
+ChatGPT exports every conversation with its full tree of edits and regenerations in `conversations.json`, which maps
+onto a ThreadTree with the message the conversation was left at (`current_node`) as the latest one:
+
+	f, err := os.Open("conversations.json")
+	report, err := importer.ImportChatGPT(f, backend, ctx)
+*/
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	Impl "github.com/yashbonde/vriksham/impl"
+)
+
+// ChatGPTConversation is a conversation in `conversations.json`, only the fields that are imported are listed
+type ChatGPTConversation struct {
+	Id             string                 `json:"id"`
+	ConversationId string                 `json:"conversation_id"`
+	Title          string                 `json:"title"`
+	CurrentNode    string                 `json:"current_node"`
+	Mapping        map[string]ChatGPTNode `json:"mapping"`
+}
+
+// ChatGPTNode is a node of the tree, the root and some bookkeeping nodes have no message
+type ChatGPTNode struct {
+	Id       string          `json:"id"`
+	Message  *ChatGPTMessage `json:"message"`
+	Parent   *string         `json:"parent"`
+	Children []string        `json:"children"`
+}
+
+type ChatGPTMessage struct {
+	Id     string `json:"id"`
+	Author struct {
+		Role string `json:"role"`
+		Name string `json:"name"`
+	} `json:"author"`
+	CreateTime *float64 `json:"create_time"`
+	Content    struct {
+		ContentType string `json:"content_type"`
+		Parts       []any  `json:"parts"`
+		Text        string `json:"text"`
+		Result      string `json:"result"`
+	} `json:"content"`
+	Recipient string         `json:"recipient"`
+	Metadata  map[string]any `json:"metadata"`
+}
+
+// Report says what ImportChatGPT did with each conversation
+type Report struct {
+	Loaded  []string `json:"loaded"`
+	Skipped []string `json:"skipped"` // already stored, they are not merged
+}
+
+/*
+ImportChatGPT reads `conversations.json` from `r` and stores every conversation with AddTree under its conversation
+id. The file is decoded one conversation at a time so large exports do not have to fit in memory.
+
+Conversations that are already stored are skipped, so running the import again with a newer export only adds the new
+conversations. It stops at the first conversation that cannot be stored, the report lists the ones done before it.
+*/
+func ImportChatGPT(r io.Reader, engine Impl.TreeEngine, ctx context.Context) (Report, error) {
+	report := Report{Loaded: []string{}, Skipped: []string{}}
+	dec := json.NewDecoder(r)
+	if tok, err := dec.Token(); err != nil {
+		return report, fmt.Errorf("reading conversations: %w", err)
+	} else if tok != json.Delim('[') {
+		return report, fmt.Errorf("reading conversations: expected a list, got %v", tok)
+	}
+	for dec.More() {
+		conv := ChatGPTConversation{}
+		if err := dec.Decode(&conv); err != nil {
+			return report, fmt.Errorf("reading conversation %d: %w", len(report.Loaded)+len(report.Skipped), err)
+		}
+		tree, err := ChatGPTTree(conv)
+		if err != nil {
+			return report, err
+		}
+		threadId := tree.Root.ThreadId
+		if _, err := engine.Size(threadId, ctx); err == nil {
+			report.Skipped = append(report.Skipped, threadId)
+			continue
+		} else if !errors.Is(err, Impl.ErrThreadNotFound) {
+			return report, err
+		}
+		if err := engine.AddTree(threadId, tree, ctx); err != nil {
+			return report, fmt.Errorf("storing conversation %s: %w", threadId, err)
+		}
+		report.Loaded = append(report.Loaded, threadId)
+	}
+	return report, nil
+}
+
+/*
+ChatGPTTree converts a single conversation. Nodes without a message are left out and their children are moved up to
+the closest message above them, or the root. The role, author name, content and creation time of a message are kept,
+the content type, model and recipient of a tool call end up in the metadata. A tool message gets its author name as
+"name" so it exports the way package export expects.
+*/
+func ChatGPTTree(conv ChatGPTConversation) (Impl.ThreadTree, error) {
+	threadId := conv.ConversationId
+	if threadId == "" {
+		threadId = conv.Id
+	}
+	if threadId == "" {
+		return Impl.ThreadTree{}, fmt.Errorf("%w: conversation %q has no id", Impl.ErrInvalidTree, conv.Title)
+	}
+	tree := Impl.ThreadTree{Root: Impl.ThreadRoot{ThreadId: threadId}}
+
+	// the tops of the tree are the nodes whose parent is missing from the mapping
+	tops := []string{}
+	for id, node := range conv.Mapping {
+		if node.Parent == nil {
+			tops = append(tops, id)
+		} else if _, ok := conv.Mapping[*node.Parent]; !ok {
+			tops = append(tops, id)
+		}
+	}
+	sort.Strings(tops)
+
+	// nearest[id] is the message the node hangs below once the empty nodes are gone
+	nearest := map[string]string{}
+	seen := map[string]bool{}
+	var visit func(id, parentId string) error
+	visit = func(id, parentId string) error {
+		if seen[id] {
+			return fmt.Errorf("%w: node %s of conversation %s is reached twice", Impl.ErrInvalidTree, id, threadId)
+		}
+		seen[id] = true
+		node, ok := conv.Mapping[id]
+		if !ok {
+			return fmt.Errorf("%w: node %s of conversation %s is missing", Impl.ErrInvalidTree, id, threadId)
+		}
+		if node.Message != nil {
+			tree.Messages = append(tree.Messages, chatGPTMessage(id, node.Message))
+			tree.Relations = append(tree.Relations, Impl.Triple{StartId: parentId, Relation: "CHILD", EndId: id})
+			parentId = id
+		}
+		nearest[id] = parentId
+		for _, child := range node.Children {
+			if err := visit(child, parentId); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, id := range tops {
+		if err := visit(id, ""); err != nil {
+			return Impl.ThreadTree{}, err
+		}
+	}
+	if len(tree.Messages) == 0 {
+		return Impl.ThreadTree{}, fmt.Errorf("%w: conversation %s has no messages", Impl.ErrInvalidTree, threadId)
+	}
+
+	if latest := nearest[conv.CurrentNode]; latest != "" {
+		for i := range tree.Messages {
+			tree.Messages[i].Latest = tree.Messages[i].MessageId == latest
+		}
+	}
+	return tree, nil
+}
+
+func chatGPTMessage(id string, cm *ChatGPTMessage) Impl.Message {
+	m := Impl.Message{
+		MessageId: id,
+		Role:      cm.Author.Role,
+		Author:    cm.Author.Name,
+		Content:   chatGPTContent(cm),
+		Metadata:  map[string]any{},
+	}
+	if cm.CreateTime != nil {
+		sec, frac := math.Modf(*cm.CreateTime)
+		m.CreatedAt = time.Unix(int64(sec), int64(frac*1e9)).UTC()
+	}
+	if cm.Content.ContentType != "" {
+		m.Metadata["content_type"] = cm.Content.ContentType
+	}
+	if model, ok := cm.Metadata["model_slug"].(string); ok && model != "" {
+		m.Metadata["model"] = model
+	}
+	if cm.Recipient != "" && cm.Recipient != "all" {
+		m.Metadata["recipient"] = cm.Recipient
+	}
+	if m.Role == "tool" && cm.Author.Name != "" {
+		m.Metadata["name"] = cm.Author.Name
+	}
+	if len(m.Metadata) == 0 {
+		m.Metadata = nil
+	}
+	return m
+}
+
+// chatGPTContent is the text of a message, parts that are not text (images, files) are left out
+func chatGPTContent(cm *ChatGPTMessage) string {
+	if cm.Content.Text != "" {
+		return cm.Content.Text
+	} else if cm.Content.Result != "" {
+		return cm.Content.Result
+	}
+	parts := []string{}
+	for _, p := range cm.Content.Parts {
+		if s, ok := p.(string); ok && s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
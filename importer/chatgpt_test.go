@@ -0,0 +1,135 @@
+package importer_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	Impl "github.com/yashbonde/vriksham/impl"
+	"github.com/yashbonde/vriksham/importer"
+)
+
+// conversations is a trimmed down export with two conversations. The first one has an empty root, a hidden system
+// message, a question that was edited (q1 and q1b) and a regenerated answer on the edit, it was left at a1c.
+const conversations = `[
+  {
+    "title": "Banyan trees",
+    "id": "conv-1",
+    "conversation_id": "conv-1",
+    "current_node": "a1c",
+    "mapping": {
+      "root": {"id": "root", "message": null, "parent": null, "children": ["sys"]},
+      "sys": {"id": "sys", "parent": "root", "children": ["q1", "q1b"], "message": {
+        "id": "sys", "author": {"role": "system"}, "content": {"content_type": "text", "parts": [""]}, "recipient": "all"}},
+      "q1": {"id": "q1", "parent": "sys", "children": ["a1"], "message": {
+        "id": "q1", "author": {"role": "user"}, "create_time": 1700000000.5,
+        "content": {"content_type": "text", "parts": ["How old do banyans get?"]}}},
+      "a1": {"id": "a1", "parent": "q1", "children": [], "message": {
+        "id": "a1", "author": {"role": "assistant"}, "metadata": {"model_slug": "gpt-4o"},
+        "content": {"content_type": "text", "parts": ["Centuries."]}}},
+      "q1b": {"id": "q1b", "parent": "sys", "children": ["a1b", "a1c"], "message": {
+        "id": "q1b", "author": {"role": "user"}, "content": {"content_type": "multimodal_text", "parts": [{"asset": "x"}, "How old is the oldest banyan?"]}}},
+      "a1b": {"id": "a1b", "parent": "q1b", "children": [], "message": {
+        "id": "a1b", "author": {"role": "assistant"}, "content": {"content_type": "text", "parts": ["Unsure."]}}},
+      "a1c": {"id": "a1c", "parent": "q1b", "children": [], "message": {
+        "id": "a1c", "author": {"role": "assistant"}, "content": {"content_type": "text", "parts": ["About 700 years."]}}}
+    }
+  },
+  {
+    "title": "Search",
+    "id": "conv-2",
+    "current_node": "t",
+    "mapping": {
+      "q": {"id": "q", "parent": null, "children": ["c"], "message": {
+        "id": "q", "author": {"role": "user"}, "content": {"content_type": "text", "parts": ["news?"]}}},
+      "c": {"id": "c", "parent": "q", "children": ["t"], "message": {
+        "id": "c", "author": {"role": "assistant"}, "recipient": "browser", "content": {"content_type": "code", "text": "search(\"news\")"}}},
+      "t": {"id": "t", "parent": "c", "children": [], "message": {
+        "id": "t", "author": {"role": "tool", "name": "browser"}, "content": {"content_type": "tether_browsing_display", "result": "3 results"}}}
+    }
+  }
+]`
+
+func TestImportChatGPT(t *testing.T) {
+	ctx := context.Background()
+	engine := &Impl.Backend_Memory{}
+	report, err := importer.ImportChatGPT(strings.NewReader(conversations), engine, ctx)
+	if err != nil {
+		t.Fatalf("ImportChatGPT: %v", err)
+	}
+	if strings.Join(report.Loaded, ",") != "conv-1,conv-2" || len(report.Skipped) != 0 {
+		t.Errorf("ImportChatGPT: got %+v", report)
+	}
+
+	tree, err := engine.Get("conv-1", ctx)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	want := `<ThreadRoot: conv-1>
+╰── [sys]
+    ├── [q1]
+    │   ╰── [a1]
+    ╰── [q1b]
+        ├── [a1b]
+        ╰── [a1c] (latest)
+`
+	if got := Impl.RenderTree(tree, Impl.RenderOptions{MarkLatest: true}); got != want {
+		t.Errorf("conv-1:\n%s\nwant\n%s", got, want)
+	}
+
+	thread, err := engine.Pick("conv-1", nil, &Impl.Message{MessageId: "a1"}, ctx)
+	if err != nil {
+		t.Fatalf("Pick: %v", err)
+	}
+	q1, a1 := thread.Messages[1], thread.Messages[2]
+	if q1.Role != "user" || q1.Content != "How old do banyans get?" || !q1.CreatedAt.Equal(time.Unix(1700000000, 5e8)) {
+		t.Errorf("q1: got %+v", q1)
+	}
+	if a1.Role != "assistant" || a1.Metadata["model"] != "gpt-4o" {
+		t.Errorf("a1: got %+v", a1)
+	}
+	latest, err := engine.GetLatestMessage("conv-1", ctx)
+	if err != nil || latest.Content != "About 700 years." {
+		t.Errorf("latest: got %+v, %v", latest, err)
+	}
+
+	thread, err = engine.Pick("conv-2", nil, nil, ctx)
+	if err != nil || len(thread.Messages) != 3 {
+		t.Fatalf("Pick conv-2: got %+v, %v", thread, err)
+	}
+	call, result := thread.Messages[1], thread.Messages[2]
+	if call.Content != `search("news")` || call.Metadata["recipient"] != "browser" {
+		t.Errorf("call: got %+v", call)
+	}
+	if result.Content != "3 results" || result.Author != "browser" || result.Metadata["name"] != "browser" {
+		t.Errorf("result: got %+v", result)
+	}
+
+	// a second import leaves the stored conversations alone
+	report, err = importer.ImportChatGPT(strings.NewReader(conversations), engine, ctx)
+	if err != nil || len(report.Loaded) != 0 || len(report.Skipped) != 2 {
+		t.Errorf("second import: got %+v, %v", report, err)
+	}
+}
+
+func TestImportChatGPTErrors(t *testing.T) {
+	ctx := context.Background()
+	for name, data := range map[string]string{
+		"not a list":  `{"id": "conv-1"}`,
+		"bad json":    `[{"id": `,
+		"no id":       `[{"mapping": {"q": {"id": "q", "message": {"author": {"role": "user"}}}}}]`,
+		"no messages": `[{"id": "conv-1", "mapping": {"root": {"id": "root", "message": null}}}]`,
+	} {
+		if _, err := importer.ImportChatGPT(strings.NewReader(data), &Impl.Backend_Memory{}, ctx); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	_, err := importer.ImportChatGPT(strings.NewReader(`[{"id": "c", "mapping": {"q": {"id": "q", "children": ["gone"],
+		"message": {"author": {"role": "user"}}}}}]`), &Impl.Backend_Memory{}, ctx)
+	if !errors.Is(err, Impl.ErrInvalidTree) {
+		t.Errorf("missing child: got %v, want ErrInvalidTree", err)
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yashbonde/vriksham/export"
+	Impl "github.com/yashbonde/vriksham/impl"
+)
+
+// execute runs a command the way main does and returns what it printed
+func execute(t *testing.T, c cli, args ...string) (string, error) {
+	t.Helper()
+	command, ok := lookup(args[0])
+	if !ok {
+		t.Fatalf("unknown command %q", args[0])
+	}
+	out := &bytes.Buffer{}
+	c.out = out
+	err := command.run(c, args[1:], context.Background())
+	return out.String(), err
+}
+
+func TestCommands(t *testing.T) {
+	c := cli{engine: &Impl.Backend_Memory{}, threadId: "cli_thread"}
+	if _, err := execute(t, c, "load-demo"); err != nil {
+		t.Fatalf("load-demo: %v", err)
+	}
+
+	out, err := execute(t, c, "pick", "msg_06", "msg_21")
+	if err != nil {
+		t.Fatalf("pick: %v", err)
+	}
+	if want := "msg_06\nmsg_16\nmsg_17\nmsg_20\nmsg_21\n"; out != want {
+		t.Errorf("pick: got %q, want %q", out, want)
+	}
+
+	out, err = execute(t, c, "add", "-role", "user", "-content", "hello", "-metadata", `{"model": "x"}`, "new_00", "msg_27")
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if want := "new_00 [user] hello\n"; out != want {
+		t.Errorf("add: got %q, want %q", out, want)
+	}
+
+	out, err = execute(t, c, "children", "msg_27")
+	if err != nil {
+		t.Fatalf("children: %v", err)
+	}
+	if want := "<ThreadRoot: cli_thread>\n╰── [msg_27] (latest)\n    ╰── [new_00]\n"; out != want {
+		t.Errorf("children: got %q, want %q", out, want)
+	}
+	out, err = execute(t, c, "children", "-latest=false", "-meta", "msg_27")
+	if err != nil {
+		t.Fatalf("children -meta: %v", err)
+	}
+	if want := "<ThreadRoot: cli_thread>\n╰── [msg_27]\n    ╰── [new_00] role=user content=\"hello\" metadata={\"model\":\"x\"}\n"; out != want {
+		t.Errorf("children -meta: got %q, want %q", out, want)
+	}
+
+	chat := c
+	chat.threadId = "chat_thread"
+	err = chat.engine.AddTree(chat.threadId, Impl.ThreadTree{
+		Root: Impl.ThreadRoot{ThreadId: chat.threadId},
+		Messages: []Impl.Message{
+			{MessageId: "u0", Role: "user", Content: "hello"},
+			{MessageId: "a0", Role: "assistant", Content: "hi", Latest: true},
+		},
+		Relations: []Impl.Triple{{Relation: "CHILD", EndId: "u0"}, {StartId: "u0", Relation: "CHILD", EndId: "a0"}},
+	}, context.Background())
+	if err != nil {
+		t.Fatalf("AddTree: %v", err)
+	}
+	out, err = execute(t, chat, "export", "-format", "anthropic")
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if want := `"text": "hi"`; !strings.Contains(out, want) {
+		t.Errorf("export: got %q, want it to contain %q", out, want)
+	}
+	out, err = execute(t, chat, "sft", "-format", "sharegpt")
+	if err != nil {
+		t.Fatalf("sft: %v", err)
+	}
+	if want := `{"id":"chat_thread/a0","conversations":[{"from":"human","value":"hello"},{"from":"gpt","value":"hi"}]}` + "\n"; out != want {
+		t.Errorf("sft: got %q, want %q", out, want)
+	}
+	for _, args := range [][]string{
+		{"add", "-role", "assistant", "-content", "hello", "-thumbs", "1", "a1", "u0"},
+		{"add", "-role", "assistant", "-content", "hey", "-thumbs", "-1", "a2", "u0"},
+	} {
+		if _, err := execute(t, chat, args...); err != nil {
+			t.Fatalf("%s: %v", strings.Join(args, " "), err)
+		}
+	}
+	out, err = execute(t, chat, "dpo")
+	if err != nil {
+		t.Fatalf("dpo: %v", err)
+	}
+	if want := `{"prompt":[{"role":"user","content":"hello"}],"chosen":[{"role":"assistant","content":"hello"}],"rejected":[{"role":"assistant","content":"hey"}]}` + "\n"; out != want {
+		t.Errorf("dpo: got %q, want %q", out, want)
+	}
+	out, err = execute(t, c, "threads", "-limit", "1", "-metadata", `{"model": "x"}`)
+	if want := "cli_thread\tsize 29\tdepth 9\tupdated -\tlatest msg_27\n"; err != nil || out != want {
+		t.Errorf("threads: got %q, %v, want %q", out, err, want)
+	}
+	out, err = execute(t, c, "threads", "-limit", "1")
+	if want := "next: vriksham threads -cursor "; err != nil || !strings.HasPrefix(out, "chat_thread\t") || !strings.Contains(out, want) {
+		t.Errorf("threads: got %q, %v, want chat_thread and a cursor", out, err)
+	}
+	if _, err := execute(t, c, "dpo", "-all", "chat_thread"); !errors.Is(err, errUsage) {
+		t.Errorf("dpo: got %v for -all with thread ids, want errUsage", err)
+	}
+
+	out, err = execute(t, chat, "export", "--format", "mermaid", "-labels")
+	if err != nil {
+		t.Fatalf("export mermaid: %v", err)
+	}
+	if want := `n1["a0<br/>assistant: hi"]`; !strings.Contains(out, want) {
+		t.Errorf("export mermaid: got %q, want it to contain %q", out, want)
+	}
+	if _, err := execute(t, c, "export", "msg_27"); !errors.Is(err, export.ErrUnknownRole) {
+		t.Errorf("export: got %v for messages without a role, want ErrUnknownRole", err)
+	}
+
+	if _, err := execute(t, c, "migrate"); err == nil {
+		t.Errorf("migrate: got no error for the memory backend")
+	}
+	sqlite := &Impl.Backend_SQLite{Path: ":memory:"}
+	if err := sqlite.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer sqlite.Close()
+	out, err = execute(t, cli{engine: sqlite}, "migrate")
+	if want := "schema is at version 4\n"; err != nil || out != want {
+		t.Errorf("migrate: got %q, %v, want %q", out, err, want)
+	}
+
+	out, err = execute(t, c, "info")
+	if want := "backend:      memory\ncapabilities: none\n"; err != nil || out != want {
+		t.Errorf("info: got %q, %v, want %q", out, err, want)
+	}
+
+	c.json = true
+	out, err = execute(t, c, "stats")
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	s := stats{}
+	if err := json.Unmarshal([]byte(out), &s); err != nil {
+		t.Fatalf("stats: %v in %q", err, out)
+	}
+	if s != (stats{Size: 29, Breadth: 9, Depth: 9, Degree: 6}) {
+		t.Errorf("stats: got %+v", s)
+	}
+
+	out, err = execute(t, c, "set-latest", "new_00")
+	if err != nil {
+		t.Fatalf("set-latest: %v", err)
+	}
+	m := Impl.Message{}
+	if err := json.Unmarshal([]byte(out), &m); err != nil || m.MessageId != "new_00" || !m.Latest {
+		t.Errorf("set-latest: got %q, %v", out, err)
+	}
+
+	if _, err := execute(t, c, "delete"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := execute(t, c, "get"); !errors.Is(err, Impl.ErrThreadNotFound) {
+		t.Errorf("get after delete: got %v, want ErrThreadNotFound", err)
+	}
+}
+
+func TestCommandArguments(t *testing.T) {
+	c := cli{engine: &Impl.Backend_Memory{}, threadId: "cli_thread"}
+	for _, args := range [][]string{
+		{"add"},
+		{"get", "msg_00"},
+		{"pick", "msg_00", "msg_06", "msg_14"},
+		{"set-latest"},
+		{"import", "chatgpt"},
+		{"export", "-format", "dot", "msg_00"},
+	} {
+		if _, err := execute(t, c, args...); !errors.Is(err, errUsage) {
+			t.Errorf("%s: got %v, want errUsage", strings.Join(args, " "), err)
+		}
+	}
+	if _, err := execute(t, c, "import", "claude", "conversations.json"); err == nil {
+		t.Errorf("import: expected an error for an unknown source")
+	}
+	if _, err := execute(t, c, "add", "-metadata", "[", "new_00"); err == nil {
+		t.Errorf("add: expected an error for bad metadata")
+	}
+}
@@ -0,0 +1,249 @@
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+
+	Impl "github.com/yashbonde/vriksham/impl"
+	"github.com/yashbonde/vriksham/rpc/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Client implements TreeEngine on top of a remote Server, errors from the server come back as the errors of the impl
+// package so `errors.Is` works the same as with a local backend
+type Client struct {
+	conn   *grpc.ClientConn
+	engine pb.TreeEngineClient
+	health healthpb.HealthClient
+}
+
+// Dial connects to the server at `target`, close the client when done
+func Dial(target string, opts ...grpc.DialOption) (*Client, error) {
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, engine: pb.NewTreeEngineClient(conn), health: healthpb.NewHealthClient(conn)}, nil
+}
+
+// the client opens grpc://host:port DSNs with Impl.Open, grpcs:// uses TLS with the certificates of the system
+func init() {
+	for _, scheme := range []string{"grpc", "grpcs"} {
+		Impl.Register(scheme, func(ctx context.Context, u *url.URL) (Impl.TreeEngine, error) {
+			creds := insecure.NewCredentials()
+			if u.Scheme == "grpcs" {
+				creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+			}
+			client, err := Dial(u.Host, grpc.WithTransportCredentials(creds))
+			if err != nil {
+				return nil, err
+			}
+			if err := client.Connect(ctx); err != nil {
+				client.Close()
+				return nil, err
+			}
+			return client, nil
+		})
+	}
+}
+
+// NewClient uses a connection owned by the caller, Close does not close it
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{engine: pb.NewTreeEngineClient(conn), health: healthpb.NewHealthClient(conn)}
+}
+
+// Connect checks the server is ready, the connection itself is opened by the first call
+func (c Client) Connect(ctx context.Context) error {
+	return c.Ping(ctx)
+}
+
+func (c *Client) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// Ping asks the health service of the server whether its engine is ready, see RegisterHealth
+func (c Client) Ping(ctx context.Context) error {
+	resp, err := c.health.Check(ctx, &healthpb.HealthCheckRequest{Service: pb.TreeEngine_ServiceDesc.ServiceName})
+	if err != nil {
+		return fromStatus(err)
+	} else if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("%w: the server is %s", Impl.ErrNotConnected, resp.Status)
+	}
+	return nil
+}
+
+func (c Client) ListThreads(opts Impl.ListOptions, ctx context.Context) (Impl.ThreadPage, error) {
+	req := &pb.ListThreadsRequest{Limit: int32(opts.Limit), Cursor: opts.Cursor, OrderBy: opts.OrderBy}
+	if len(opts.Metadata) > 0 {
+		metadata, err := structpb.NewStruct(opts.Metadata)
+		if err != nil {
+			return Impl.ThreadPage{}, fmt.Errorf("%w: metadata filter: %v", Impl.ErrInvalidListOptions, err)
+		}
+		req.Metadata = metadata
+	}
+	page, err := c.engine.ListThreads(ctx, req)
+	if err != nil {
+		return Impl.ThreadPage{}, fromStatus(err)
+	}
+	return pageFromProto(page), nil
+}
+
+// Info describes the client, with what the server says about its engine in Remote
+func (c Client) Info(ctx context.Context) (Impl.BackendInfo, error) {
+	remote, err := c.engine.Info(ctx, &emptypb.Empty{})
+	if err != nil {
+		return Impl.RemoteInfo("grpc", Impl.BackendInfo{}), fromStatus(err)
+	}
+	return Impl.RemoteInfo("grpc", Impl.BackendInfo{
+		Name:         remote.Name,
+		Version:      remote.Version,
+		Capabilities: append([]string{}, remote.Capabilities...),
+	}), nil
+}
+
+// fromStatus rebuilds the error the server sent
+func fromStatus(err error) error {
+	st, ok := status.FromError(err)
+	if !ok || err == nil {
+		return err
+	}
+	for _, d := range st.Details() {
+		if detail, ok := d.(*pb.ErrorDetail); ok {
+			return Impl.ErrorFromCode(detail.Code, st.Message(), detail.ThreadId, detail.MessageId)
+		}
+	}
+	return err
+}
+
+// implement interface
+
+func (c Client) AddMessage(threadId string, a, b *Impl.Message, ctx context.Context) error {
+	req := &pb.AddMessageRequest{ThreadId: threadId, ParentId: messageId(b)}
+	if a != nil {
+		m, err := messageToProto(*a)
+		if err != nil {
+			return err
+		}
+		req.Message = m
+	}
+	_, err := c.engine.AddMessage(ctx, req)
+	return fromStatus(err)
+}
+
+func (c Client) AddTree(threadId string, tree Impl.ThreadTree, ctx context.Context) error {
+	pt, err := treeToProto(tree)
+	if err != nil {
+		return err
+	}
+	_, err = c.engine.AddTree(ctx, &pb.AddTreeRequest{ThreadId: threadId, Tree: pt})
+	return fromStatus(err)
+}
+
+func (c Client) Breadth(threadId string, ctx context.Context) (int, error) {
+	n, err := c.engine.Breadth(ctx, &pb.ThreadRequest{ThreadId: threadId})
+	return int(n.GetCount()), fromStatus(err)
+}
+
+func (c Client) Degree(threadId string, message *Impl.Message, ctx context.Context) (int, error) {
+	n, err := c.engine.Degree(ctx, &pb.MessageRequest{ThreadId: threadId, MessageId: messageId(message)})
+	return int(n.GetCount()), fromStatus(err)
+}
+
+func (c Client) Delete(threadId string, message *Impl.Message, ctx context.Context) error {
+	_, err := c.engine.Delete(ctx, &pb.MessageRequest{ThreadId: threadId, MessageId: messageId(message)})
+	return fromStatus(err)
+}
+
+func (c Client) Depth(threadId string, ctx context.Context) (int, error) {
+	n, err := c.engine.Depth(ctx, &pb.ThreadRequest{ThreadId: threadId})
+	return int(n.GetCount()), fromStatus(err)
+}
+
+func (c Client) Get(threadId string, ctx context.Context) (Impl.ThreadTree, error) {
+	tree, err := c.engine.Get(ctx, &pb.ThreadRequest{ThreadId: threadId})
+	if err != nil {
+		return Impl.ThreadTree{}, fromStatus(err)
+	}
+	return treeFromProto(tree), nil
+}
+
+func (c Client) GetChildren(threadId string, message *Impl.Message, depth int, ctx context.Context) (Impl.ThreadTree, error) {
+	tree, err := c.engine.GetChildren(ctx, &pb.GetChildrenRequest{
+		ThreadId:  threadId,
+		MessageId: messageId(message),
+		Depth:     int32(depth),
+	})
+	if err != nil {
+		return Impl.ThreadTree{}, fromStatus(err)
+	}
+	return treeFromProto(tree), nil
+}
+
+func (c Client) GetLatestMessage(threadId string, ctx context.Context) (Impl.Message, error) {
+	m, err := c.engine.GetLatestMessage(ctx, &pb.ThreadRequest{ThreadId: threadId})
+	if err != nil {
+		return Impl.Message{}, fromStatus(err)
+	}
+	return messageFromProto(m), nil
+}
+
+func (c Client) Pick(threadId string, a, b *Impl.Message, ctx context.Context) (Impl.Thread, error) {
+	thread, err := c.engine.Pick(ctx, &pb.PickRequest{ThreadId: threadId, FromId: messageId(a), ToId: messageId(b)})
+	if err != nil {
+		return Impl.Thread{}, fromStatus(err)
+	}
+	return Impl.Thread{Messages: messagesFromProto(thread.Messages)}, nil
+}
+
+func (c Client) SetLatestMessage(threadId string, latestMessage *Impl.Message, ctx context.Context) (Impl.Message, error) {
+	m, err := c.engine.SetLatestMessage(ctx, &pb.MessageRequest{ThreadId: threadId, MessageId: messageId(latestMessage)})
+	if err != nil {
+		return Impl.Message{}, fromStatus(err)
+	}
+	return messageFromProto(m), nil
+}
+
+func (c Client) Size(threadId string, ctx context.Context) (int, error) {
+	n, err := c.engine.Size(ctx, &pb.ThreadRequest{ThreadId: threadId})
+	return int(n.GetCount()), fromStatus(err)
+}
+
+// StreamTree calls `visit` with every chunk of the tree as it arrives, each chunk is a ThreadTree with a part of the
+// messages and the relations ending at them. A `chunkSize` of 0 leaves it to the server.
+func (c Client) StreamTree(threadId string, chunkSize int, visit func(chunk Impl.ThreadTree) error, ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.engine.StreamTree(ctx, &pb.StreamTreeRequest{ThreadId: threadId, ChunkSize: int32(chunkSize)})
+	if err != nil {
+		return fromStatus(err)
+	}
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fromStatus(err)
+		}
+		err = visit(Impl.ThreadTree{
+			Root:      Impl.ThreadRoot{ThreadId: threadId},
+			Messages:  messagesFromProto(chunk.Messages),
+			Relations: triplesFromProto(chunk.Relations),
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
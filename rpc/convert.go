@@ -0,0 +1,157 @@
+package rpc
+
+import (
+	"fmt"
+
+	Impl "github.com/yashbonde/vriksham/impl"
+	"github.com/yashbonde/vriksham/rpc/pb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// conversions between the types of the impl package and their protobuf messages, metadata goes through
+// google.protobuf.Struct so it takes the same values as JSON
+
+func messageToProto(m Impl.Message) (*pb.Message, error) {
+	out := &pb.Message{Id: m.MessageId, Latest: m.Latest, Role: m.Role, Content: m.Content, Author: m.Author}
+	if !m.CreatedAt.IsZero() {
+		out.CreatedAt = timestamppb.New(m.CreatedAt)
+	}
+	if len(m.Metadata) > 0 {
+		metadata, err := structpb.NewStruct(m.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("%w: metadata of %s: %v", Impl.ErrInvalidMessage, m.MessageId, err)
+		}
+		out.Metadata = metadata
+	}
+	if m.Rating != nil {
+		out.Rating = &pb.Rating{Thumbs: int32(m.Rating.Thumbs), Score: m.Rating.Score}
+	}
+	return out, nil
+}
+
+func messageFromProto(m *pb.Message) Impl.Message {
+	if m == nil {
+		return Impl.Message{}
+	}
+	out := Impl.Message{MessageId: m.Id, Latest: m.Latest, Role: m.Role, Content: m.Content, Author: m.Author}
+	if m.CreatedAt != nil {
+		out.CreatedAt = m.CreatedAt.AsTime()
+	}
+	if len(m.Metadata.GetFields()) > 0 {
+		out.Metadata = m.Metadata.AsMap()
+	}
+	if m.Rating != nil {
+		out.Rating = &Impl.Rating{Thumbs: int(m.Rating.Thumbs), Score: m.Rating.Score}
+	}
+	return out
+}
+
+func messagesToProto(messages []Impl.Message) ([]*pb.Message, error) {
+	out := make([]*pb.Message, 0, len(messages))
+	for _, m := range messages {
+		pm, err := messageToProto(m)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, pm)
+	}
+	return out, nil
+}
+
+func messagesFromProto(messages []*pb.Message) []Impl.Message {
+	out := make([]Impl.Message, 0, len(messages))
+	for _, m := range messages {
+		out = append(out, messageFromProto(m))
+	}
+	return out
+}
+
+func tripleToProto(r Impl.Triple) *pb.Triple {
+	return &pb.Triple{StartId: r.StartId, Relation: r.Relation, EndId: r.EndId}
+}
+
+func triplesFromProto(relations []*pb.Triple) []Impl.Triple {
+	out := make([]Impl.Triple, 0, len(relations))
+	for _, r := range relations {
+		out = append(out, Impl.Triple{StartId: r.StartId, Relation: r.Relation, EndId: r.EndId})
+	}
+	return out
+}
+
+func treeToProto(tree Impl.ThreadTree) (*pb.ThreadTree, error) {
+	messages, err := messagesToProto(tree.Messages)
+	if err != nil {
+		return nil, err
+	}
+	out := &pb.ThreadTree{ThreadId: tree.Root.ThreadId, Messages: messages}
+	for _, r := range tree.Relations {
+		out.Relations = append(out.Relations, tripleToProto(r))
+	}
+	return out, nil
+}
+
+func treeFromProto(tree *pb.ThreadTree) Impl.ThreadTree {
+	return Impl.ThreadTree{
+		Root:      Impl.ThreadRoot{ThreadId: tree.GetThreadId()},
+		Messages:  messagesFromProto(tree.GetMessages()),
+		Relations: triplesFromProto(tree.GetRelations()),
+	}
+}
+
+// message turns an optional id from a request into a message, nil when it is empty
+func message(id string) *Impl.Message {
+	if id == "" {
+		return nil
+	}
+	return &Impl.Message{MessageId: id}
+}
+
+// messageId is the other way around
+func messageId(m *Impl.Message) string {
+	if m == nil {
+		return ""
+	}
+	return m.MessageId
+}
+
+func pageToProto(page Impl.ThreadPage) (*pb.ThreadPage, error) {
+	out := &pb.ThreadPage{NextCursor: page.NextCursor}
+	for _, s := range page.Threads {
+		summary := &pb.ThreadSummary{ThreadId: s.ThreadId, Size: int64(s.Size), Depth: int64(s.Depth)}
+		if !s.CreatedAt.IsZero() {
+			summary.CreatedAt = timestamppb.New(s.CreatedAt)
+		}
+		if !s.UpdatedAt.IsZero() {
+			summary.UpdatedAt = timestamppb.New(s.UpdatedAt)
+		}
+		if s.Latest != nil {
+			latest, err := messageToProto(*s.Latest)
+			if err != nil {
+				return nil, err
+			}
+			summary.Latest = latest
+		}
+		out.Threads = append(out.Threads, summary)
+	}
+	return out, nil
+}
+
+func pageFromProto(page *pb.ThreadPage) Impl.ThreadPage {
+	out := Impl.ThreadPage{Threads: []Impl.ThreadSummary{}, NextCursor: page.GetNextCursor()}
+	for _, s := range page.GetThreads() {
+		summary := Impl.ThreadSummary{ThreadId: s.ThreadId, Size: int(s.Size), Depth: int(s.Depth)}
+		if s.CreatedAt != nil {
+			summary.CreatedAt = s.CreatedAt.AsTime()
+		}
+		if s.UpdatedAt != nil {
+			summary.UpdatedAt = s.UpdatedAt.AsTime()
+		}
+		if s.Latest != nil {
+			latest := messageFromProto(s.Latest)
+			summary.Latest = &latest
+		}
+		out.Threads = append(out.Threads, summary)
+	}
+	return out
+}
@@ -0,0 +1,706 @@
+// The TreeEngine interface of github.com/yashbonde/vriksham/impl as a gRPC service, see rpc/server.go for the server
+// and rpc/client.go for a client that is a TreeEngine itself. Regenerate the go code with `buf generate` in rpc/.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: vriksham/v1/vriksham.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	TreeEngine_AddMessage_FullMethodName       = "/vriksham.v1.TreeEngine/AddMessage"
+	TreeEngine_AddTree_FullMethodName          = "/vriksham.v1.TreeEngine/AddTree"
+	TreeEngine_Breadth_FullMethodName          = "/vriksham.v1.TreeEngine/Breadth"
+	TreeEngine_Degree_FullMethodName           = "/vriksham.v1.TreeEngine/Degree"
+	TreeEngine_Delete_FullMethodName           = "/vriksham.v1.TreeEngine/Delete"
+	TreeEngine_Depth_FullMethodName            = "/vriksham.v1.TreeEngine/Depth"
+	TreeEngine_Get_FullMethodName              = "/vriksham.v1.TreeEngine/Get"
+	TreeEngine_GetChildren_FullMethodName      = "/vriksham.v1.TreeEngine/GetChildren"
+	TreeEngine_GetLatestMessage_FullMethodName = "/vriksham.v1.TreeEngine/GetLatestMessage"
+	TreeEngine_Pick_FullMethodName             = "/vriksham.v1.TreeEngine/Pick"
+	TreeEngine_SetLatestMessage_FullMethodName = "/vriksham.v1.TreeEngine/SetLatestMessage"
+	TreeEngine_Size_FullMethodName             = "/vriksham.v1.TreeEngine/Size"
+	TreeEngine_StreamTree_FullMethodName       = "/vriksham.v1.TreeEngine/StreamTree"
+	TreeEngine_ListThreads_FullMethodName      = "/vriksham.v1.TreeEngine/ListThreads"
+	TreeEngine_Info_FullMethodName             = "/vriksham.v1.TreeEngine/Info"
+)
+
+// TreeEngineClient is the client API for TreeEngine service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TreeEngineClient interface {
+	// AddMessage adds a message below `parent_id`, or below the root when it is empty
+	AddMessage(ctx context.Context, in *AddMessageRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// AddTree merges an entire tree into the thread
+	AddTree(ctx context.Context, in *AddTreeRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Breadth is the number of leaves
+	Breadth(ctx context.Context, in *ThreadRequest, opts ...grpc.CallOption) (*Count, error)
+	// Degree is the number of children of `message_id`, or of the root when it is empty
+	Degree(ctx context.Context, in *MessageRequest, opts ...grpc.CallOption) (*Count, error)
+	// Delete removes `message_id` and everything below it, or the whole thread when it is empty
+	Delete(ctx context.Context, in *MessageRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Depth is the maximum level of any message in the thread
+	Depth(ctx context.Context, in *ThreadRequest, opts ...grpc.CallOption) (*Count, error)
+	// Get returns the entire tree
+	Get(ctx context.Context, in *ThreadRequest, opts ...grpc.CallOption) (*ThreadTree, error)
+	// GetChildren returns `depth` levels starting at `message_id`, or at the root when it is empty
+	GetChildren(ctx context.Context, in *GetChildrenRequest, opts ...grpc.CallOption) (*ThreadTree, error)
+	// GetLatestMessage returns the latest message of the thread
+	GetLatestMessage(ctx context.Context, in *ThreadRequest, opts ...grpc.CallOption) (*Message, error)
+	// Pick returns the messages from `from_id` (default the root) to `to_id` (default the latest message)
+	Pick(ctx context.Context, in *PickRequest, opts ...grpc.CallOption) (*Thread, error)
+	// SetLatestMessage marks `message_id` as the latest message and returns it
+	SetLatestMessage(ctx context.Context, in *MessageRequest, opts ...grpc.CallOption) (*Message, error)
+	// Size is the number of messages in the thread
+	Size(ctx context.Context, in *ThreadRequest, opts ...grpc.CallOption) (*Count, error)
+	// StreamTree sends the tree returned by Get in chunks of at most `chunk_size` messages, every relation comes in the
+	// chunk with the message it ends at
+	StreamTree(ctx context.Context, in *StreamTreeRequest, opts ...grpc.CallOption) (TreeEngine_StreamTreeClient, error)
+	// ListThreads returns a page of the stored threads, see impl.ListOptions for the fields of the request
+	ListThreads(ctx context.Context, in *ListThreadsRequest, opts ...grpc.CallOption) (*ThreadPage, error)
+	// Info describes the engine behind the server, readiness is served by the standard grpc.health.v1 service
+	Info(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*BackendInfo, error)
+}
+
+type treeEngineClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTreeEngineClient(cc grpc.ClientConnInterface) TreeEngineClient {
+	return &treeEngineClient{cc}
+}
+
+func (c *treeEngineClient) AddMessage(ctx context.Context, in *AddMessageRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, TreeEngine_AddMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeEngineClient) AddTree(ctx context.Context, in *AddTreeRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, TreeEngine_AddTree_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeEngineClient) Breadth(ctx context.Context, in *ThreadRequest, opts ...grpc.CallOption) (*Count, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Count)
+	err := c.cc.Invoke(ctx, TreeEngine_Breadth_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeEngineClient) Degree(ctx context.Context, in *MessageRequest, opts ...grpc.CallOption) (*Count, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Count)
+	err := c.cc.Invoke(ctx, TreeEngine_Degree_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeEngineClient) Delete(ctx context.Context, in *MessageRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, TreeEngine_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeEngineClient) Depth(ctx context.Context, in *ThreadRequest, opts ...grpc.CallOption) (*Count, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Count)
+	err := c.cc.Invoke(ctx, TreeEngine_Depth_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeEngineClient) Get(ctx context.Context, in *ThreadRequest, opts ...grpc.CallOption) (*ThreadTree, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ThreadTree)
+	err := c.cc.Invoke(ctx, TreeEngine_Get_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeEngineClient) GetChildren(ctx context.Context, in *GetChildrenRequest, opts ...grpc.CallOption) (*ThreadTree, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ThreadTree)
+	err := c.cc.Invoke(ctx, TreeEngine_GetChildren_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeEngineClient) GetLatestMessage(ctx context.Context, in *ThreadRequest, opts ...grpc.CallOption) (*Message, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Message)
+	err := c.cc.Invoke(ctx, TreeEngine_GetLatestMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeEngineClient) Pick(ctx context.Context, in *PickRequest, opts ...grpc.CallOption) (*Thread, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Thread)
+	err := c.cc.Invoke(ctx, TreeEngine_Pick_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeEngineClient) SetLatestMessage(ctx context.Context, in *MessageRequest, opts ...grpc.CallOption) (*Message, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Message)
+	err := c.cc.Invoke(ctx, TreeEngine_SetLatestMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeEngineClient) Size(ctx context.Context, in *ThreadRequest, opts ...grpc.CallOption) (*Count, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Count)
+	err := c.cc.Invoke(ctx, TreeEngine_Size_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeEngineClient) StreamTree(ctx context.Context, in *StreamTreeRequest, opts ...grpc.CallOption) (TreeEngine_StreamTreeClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TreeEngine_ServiceDesc.Streams[0], TreeEngine_StreamTree_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &treeEngineStreamTreeClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TreeEngine_StreamTreeClient interface {
+	Recv() (*TreeChunk, error)
+	grpc.ClientStream
+}
+
+type treeEngineStreamTreeClient struct {
+	grpc.ClientStream
+}
+
+func (x *treeEngineStreamTreeClient) Recv() (*TreeChunk, error) {
+	m := new(TreeChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *treeEngineClient) ListThreads(ctx context.Context, in *ListThreadsRequest, opts ...grpc.CallOption) (*ThreadPage, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ThreadPage)
+	err := c.cc.Invoke(ctx, TreeEngine_ListThreads_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *treeEngineClient) Info(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*BackendInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BackendInfo)
+	err := c.cc.Invoke(ctx, TreeEngine_Info_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TreeEngineServer is the server API for TreeEngine service.
+// All implementations must embed UnimplementedTreeEngineServer
+// for forward compatibility
+type TreeEngineServer interface {
+	// AddMessage adds a message below `parent_id`, or below the root when it is empty
+	AddMessage(context.Context, *AddMessageRequest) (*emptypb.Empty, error)
+	// AddTree merges an entire tree into the thread
+	AddTree(context.Context, *AddTreeRequest) (*emptypb.Empty, error)
+	// Breadth is the number of leaves
+	Breadth(context.Context, *ThreadRequest) (*Count, error)
+	// Degree is the number of children of `message_id`, or of the root when it is empty
+	Degree(context.Context, *MessageRequest) (*Count, error)
+	// Delete removes `message_id` and everything below it, or the whole thread when it is empty
+	Delete(context.Context, *MessageRequest) (*emptypb.Empty, error)
+	// Depth is the maximum level of any message in the thread
+	Depth(context.Context, *ThreadRequest) (*Count, error)
+	// Get returns the entire tree
+	Get(context.Context, *ThreadRequest) (*ThreadTree, error)
+	// GetChildren returns `depth` levels starting at `message_id`, or at the root when it is empty
+	GetChildren(context.Context, *GetChildrenRequest) (*ThreadTree, error)
+	// GetLatestMessage returns the latest message of the thread
+	GetLatestMessage(context.Context, *ThreadRequest) (*Message, error)
+	// Pick returns the messages from `from_id` (default the root) to `to_id` (default the latest message)
+	Pick(context.Context, *PickRequest) (*Thread, error)
+	// SetLatestMessage marks `message_id` as the latest message and returns it
+	SetLatestMessage(context.Context, *MessageRequest) (*Message, error)
+	// Size is the number of messages in the thread
+	Size(context.Context, *ThreadRequest) (*Count, error)
+	// StreamTree sends the tree returned by Get in chunks of at most `chunk_size` messages, every relation comes in the
+	// chunk with the message it ends at
+	StreamTree(*StreamTreeRequest, TreeEngine_StreamTreeServer) error
+	// ListThreads returns a page of the stored threads, see impl.ListOptions for the fields of the request
+	ListThreads(context.Context, *ListThreadsRequest) (*ThreadPage, error)
+	// Info describes the engine behind the server, readiness is served by the standard grpc.health.v1 service
+	Info(context.Context, *emptypb.Empty) (*BackendInfo, error)
+	mustEmbedUnimplementedTreeEngineServer()
+}
+
+// UnimplementedTreeEngineServer must be embedded to have forward compatible implementations.
+type UnimplementedTreeEngineServer struct {
+}
+
+func (UnimplementedTreeEngineServer) AddMessage(context.Context, *AddMessageRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddMessage not implemented")
+}
+func (UnimplementedTreeEngineServer) AddTree(context.Context, *AddTreeRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddTree not implemented")
+}
+func (UnimplementedTreeEngineServer) Breadth(context.Context, *ThreadRequest) (*Count, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Breadth not implemented")
+}
+func (UnimplementedTreeEngineServer) Degree(context.Context, *MessageRequest) (*Count, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Degree not implemented")
+}
+func (UnimplementedTreeEngineServer) Delete(context.Context, *MessageRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedTreeEngineServer) Depth(context.Context, *ThreadRequest) (*Count, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Depth not implemented")
+}
+func (UnimplementedTreeEngineServer) Get(context.Context, *ThreadRequest) (*ThreadTree, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedTreeEngineServer) GetChildren(context.Context, *GetChildrenRequest) (*ThreadTree, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChildren not implemented")
+}
+func (UnimplementedTreeEngineServer) GetLatestMessage(context.Context, *ThreadRequest) (*Message, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLatestMessage not implemented")
+}
+func (UnimplementedTreeEngineServer) Pick(context.Context, *PickRequest) (*Thread, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Pick not implemented")
+}
+func (UnimplementedTreeEngineServer) SetLatestMessage(context.Context, *MessageRequest) (*Message, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLatestMessage not implemented")
+}
+func (UnimplementedTreeEngineServer) Size(context.Context, *ThreadRequest) (*Count, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Size not implemented")
+}
+func (UnimplementedTreeEngineServer) StreamTree(*StreamTreeRequest, TreeEngine_StreamTreeServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamTree not implemented")
+}
+func (UnimplementedTreeEngineServer) ListThreads(context.Context, *ListThreadsRequest) (*ThreadPage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListThreads not implemented")
+}
+func (UnimplementedTreeEngineServer) Info(context.Context, *emptypb.Empty) (*BackendInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Info not implemented")
+}
+func (UnimplementedTreeEngineServer) mustEmbedUnimplementedTreeEngineServer() {}
+
+// UnsafeTreeEngineServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TreeEngineServer will
+// result in compilation errors.
+type UnsafeTreeEngineServer interface {
+	mustEmbedUnimplementedTreeEngineServer()
+}
+
+func RegisterTreeEngineServer(s grpc.ServiceRegistrar, srv TreeEngineServer) {
+	s.RegisterService(&TreeEngine_ServiceDesc, srv)
+}
+
+func _TreeEngine_AddMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeEngineServer).AddMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeEngine_AddMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeEngineServer).AddMessage(ctx, req.(*AddMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeEngine_AddTree_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddTreeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeEngineServer).AddTree(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeEngine_AddTree_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeEngineServer).AddTree(ctx, req.(*AddTreeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeEngine_Breadth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ThreadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeEngineServer).Breadth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeEngine_Breadth_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeEngineServer).Breadth(ctx, req.(*ThreadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeEngine_Degree_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeEngineServer).Degree(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeEngine_Degree_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeEngineServer).Degree(ctx, req.(*MessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeEngine_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeEngineServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeEngine_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeEngineServer).Delete(ctx, req.(*MessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeEngine_Depth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ThreadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeEngineServer).Depth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeEngine_Depth_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeEngineServer).Depth(ctx, req.(*ThreadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeEngine_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ThreadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeEngineServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeEngine_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeEngineServer).Get(ctx, req.(*ThreadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeEngine_GetChildren_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetChildrenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeEngineServer).GetChildren(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeEngine_GetChildren_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeEngineServer).GetChildren(ctx, req.(*GetChildrenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeEngine_GetLatestMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ThreadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeEngineServer).GetLatestMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeEngine_GetLatestMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeEngineServer).GetLatestMessage(ctx, req.(*ThreadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeEngine_Pick_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PickRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeEngineServer).Pick(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeEngine_Pick_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeEngineServer).Pick(ctx, req.(*PickRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeEngine_SetLatestMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeEngineServer).SetLatestMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeEngine_SetLatestMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeEngineServer).SetLatestMessage(ctx, req.(*MessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeEngine_Size_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ThreadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeEngineServer).Size(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeEngine_Size_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeEngineServer).Size(ctx, req.(*ThreadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeEngine_StreamTree_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamTreeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TreeEngineServer).StreamTree(m, &treeEngineStreamTreeServer{ServerStream: stream})
+}
+
+type TreeEngine_StreamTreeServer interface {
+	Send(*TreeChunk) error
+	grpc.ServerStream
+}
+
+type treeEngineStreamTreeServer struct {
+	grpc.ServerStream
+}
+
+func (x *treeEngineStreamTreeServer) Send(m *TreeChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TreeEngine_ListThreads_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListThreadsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeEngineServer).ListThreads(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeEngine_ListThreads_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeEngineServer).ListThreads(ctx, req.(*ListThreadsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TreeEngine_Info_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TreeEngineServer).Info(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TreeEngine_Info_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TreeEngineServer).Info(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TreeEngine_ServiceDesc is the grpc.ServiceDesc for TreeEngine service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TreeEngine_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "vriksham.v1.TreeEngine",
+	HandlerType: (*TreeEngineServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddMessage",
+			Handler:    _TreeEngine_AddMessage_Handler,
+		},
+		{
+			MethodName: "AddTree",
+			Handler:    _TreeEngine_AddTree_Handler,
+		},
+		{
+			MethodName: "Breadth",
+			Handler:    _TreeEngine_Breadth_Handler,
+		},
+		{
+			MethodName: "Degree",
+			Handler:    _TreeEngine_Degree_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _TreeEngine_Delete_Handler,
+		},
+		{
+			MethodName: "Depth",
+			Handler:    _TreeEngine_Depth_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _TreeEngine_Get_Handler,
+		},
+		{
+			MethodName: "GetChildren",
+			Handler:    _TreeEngine_GetChildren_Handler,
+		},
+		{
+			MethodName: "GetLatestMessage",
+			Handler:    _TreeEngine_GetLatestMessage_Handler,
+		},
+		{
+			MethodName: "Pick",
+			Handler:    _TreeEngine_Pick_Handler,
+		},
+		{
+			MethodName: "SetLatestMessage",
+			Handler:    _TreeEngine_SetLatestMessage_Handler,
+		},
+		{
+			MethodName: "Size",
+			Handler:    _TreeEngine_Size_Handler,
+		},
+		{
+			MethodName: "ListThreads",
+			Handler:    _TreeEngine_ListThreads_Handler,
+		},
+		{
+			MethodName: "Info",
+			Handler:    _TreeEngine_Info_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamTree",
+			Handler:       _TreeEngine_StreamTree_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "vriksham/v1/vriksham.proto",
+}
@@ -0,0 +1,103 @@
+package rpc_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	Impl "github.com/yashbonde/vriksham/impl"
+	"github.com/yashbonde/vriksham/impl/enginetest"
+	"github.com/yashbonde/vriksham/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// serve starts a server for `engine` on an in-memory listener and returns a client connected to it
+func serve(t *testing.T, engine Impl.TreeEngine) *rpc.Client {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	rpc.Register(s, engine)
+	rpc.RegisterHealth(s, engine)
+	go s.Serve(listener)
+	t.Cleanup(s.Stop)
+
+	client, err := rpc.Dial("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestClient(t *testing.T) {
+	enginetest.Run(t, func(t *testing.T) Impl.TreeEngine {
+		return serve(t, &Impl.Backend_Memory{})
+	})
+}
+
+func TestStreamTree(t *testing.T) {
+	ctx := context.Background()
+	client := serve(t, &Impl.Backend_Memory{})
+	demo := Impl.GetDemoTree()
+	if err := client.AddTree(demo.Root.ThreadId, *demo, ctx); err != nil {
+		t.Fatalf("AddTree: %v", err)
+	}
+
+	chunks, messages, relations := 0, 0, 0
+	err := client.StreamTree(demo.Root.ThreadId, 10, func(chunk Impl.ThreadTree) error {
+		chunks++
+		messages += len(chunk.Messages)
+		relations += len(chunk.Relations)
+		if len(chunk.Messages) != len(chunk.Relations) {
+			t.Errorf("chunk %d: %d messages with %d relations", chunks, len(chunk.Messages), len(chunk.Relations))
+		}
+		return nil
+	}, ctx)
+	if err != nil {
+		t.Fatalf("StreamTree: %v", err)
+	}
+	if chunks != 3 || messages != 28 || relations != 28 {
+		t.Errorf("StreamTree: got %d chunks with %d messages and %d relations", chunks, messages, relations)
+	}
+
+	stop := errors.New("stop")
+	err = client.StreamTree(demo.Root.ThreadId, 10, func(Impl.ThreadTree) error { return stop }, ctx)
+	if !errors.Is(err, stop) {
+		t.Errorf("StreamTree: got %v, want the error from visit", err)
+	}
+	err = client.StreamTree("unknown", 10, func(Impl.ThreadTree) error { return nil }, ctx)
+	if !errors.Is(err, Impl.ErrThreadNotFound) {
+		t.Errorf("StreamTree(unknown): got %v, want ErrThreadNotFound", err)
+	}
+}
+
+func TestClientLifecycle(t *testing.T) {
+	ctx := context.Background()
+	engine := &Impl.Backend_Bolt{Path: filepath.Join(t.TempDir(), "vriksham.bolt")}
+	if err := engine.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	client := serve(t, engine)
+
+	if err := client.Connect(ctx); err != nil {
+		t.Errorf("Connect: %v", err)
+	}
+	info, err := client.Info(ctx)
+	want := []string{Impl.CapabilityRemote, Impl.CapabilityShared, Impl.CapabilityPersistent}
+	if err != nil || info.Name != "grpc" || !slices.Equal(info.Capabilities, want) || info.Remote == nil || info.Remote.Name != "bolt" {
+		t.Errorf("Info: got %+v, %v", info, err)
+	}
+
+	engine.Close()
+	if err := client.Ping(ctx); !errors.Is(err, Impl.ErrNotConnected) {
+		t.Errorf("Ping: got %v, want ErrNotConnected", err)
+	}
+}
@@ -0,0 +1,243 @@
+/*
+Package rpc serves a TreeEngine over gRPC and has a Client that is a TreeEngine itself, so code can switch between a
+local backend and a remote one without changes:
+
+	grpcServer := grpc.NewServer()
+	rpc.Register(grpcServer, &Impl.Backend_SQLite{Path: "threads.db"})
+
+	client, err := rpc.Dial("localhost:9090", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	tree, err := client.Get("tree_0000", ctx)
+
+RegisterHealth adds the standard grpc.health.v1 service for readiness probes, it reports SERVING while the engine
+answers Impl.Ping.
+
+The schema is in proto/vriksham/v1/vriksham.proto and the generated code in pb/.
+*/
+package rpc
+
+//go:generate buf generate
+
+import (
+	"context"
+	"errors"
+
+	Impl "github.com/yashbonde/vriksham/impl"
+	"github.com/yashbonde/vriksham/rpc/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// defaultChunkSize is the number of messages in each chunk of StreamTree when the request does not say
+const defaultChunkSize = 500
+
+// Server adapts a TreeEngine to the generated pb.TreeEngineServer
+type Server struct {
+	pb.UnimplementedTreeEngineServer
+	Engine Impl.TreeEngine
+}
+
+func NewServer(engine Impl.TreeEngine) *Server {
+	return &Server{Engine: engine}
+}
+
+// Register serves `engine` on `s`
+func Register(s *grpc.Server, engine Impl.TreeEngine) {
+	pb.RegisterTreeEngineServer(s, NewServer(engine))
+}
+
+// Health serves grpc.health.v1 for an engine, the empty service name and the name of the TreeEngine service are known
+type Health struct {
+	healthpb.UnimplementedHealthServer
+	Engine Impl.TreeEngine
+}
+
+// RegisterHealth serves the health of `engine` on `s`
+func RegisterHealth(s *grpc.Server, engine Impl.TreeEngine) {
+	healthpb.RegisterHealthServer(s, &Health{Engine: engine})
+}
+
+func (h *Health) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if req.Service != "" && req.Service != pb.TreeEngine_ServiceDesc.ServiceName {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", req.Service)
+	}
+	if err := Impl.Ping(h.Engine, ctx); err != nil {
+		return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}, nil
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+// errorStatus is the status code for each Impl.ErrorCode
+var errorStatus = map[string]codes.Code{
+	"thread_not_found":     codes.NotFound,
+	"message_not_found":    codes.NotFound,
+	"no_latest":            codes.NotFound,
+	"duplicate_message":    codes.AlreadyExists,
+	"invalid_message":      codes.InvalidArgument,
+	"invalid_tree":         codes.InvalidArgument,
+	"depth_exceeded":       codes.OutOfRange,
+	"not_connected":        codes.Unavailable,
+	"invalid_list_options": codes.InvalidArgument,
+	"unsupported":          codes.Unimplemented,
+}
+
+// toStatus turns an error of the engine into a status, the errors of the impl package carry a pb.ErrorDetail so the
+// client can rebuild them
+func toStatus(threadId string, err error) error {
+	if err == nil {
+		return nil
+	}
+	code := Impl.ErrorCode(err)
+	if code == "" {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return status.FromContextError(err).Err()
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+	detail := &pb.ErrorDetail{Code: code, ThreadId: threadId}
+	var messageErr *Impl.MessageError
+	if errors.As(err, &messageErr) {
+		detail.ThreadId, detail.MessageId = messageErr.ThreadId, messageErr.MessageId
+	}
+	st, detailErr := status.New(errorStatus[code], err.Error()).WithDetails(detail)
+	if detailErr != nil {
+		return status.Error(errorStatus[code], err.Error())
+	}
+	return st.Err()
+}
+
+func (s *Server) AddMessage(ctx context.Context, req *pb.AddMessageRequest) (*emptypb.Empty, error) {
+	m := messageFromProto(req.Message)
+	err := s.Engine.AddMessage(req.ThreadId, &m, message(req.ParentId), ctx)
+	return &emptypb.Empty{}, toStatus(req.ThreadId, err)
+}
+
+func (s *Server) AddTree(ctx context.Context, req *pb.AddTreeRequest) (*emptypb.Empty, error) {
+	err := s.Engine.AddTree(req.ThreadId, treeFromProto(req.Tree), ctx)
+	return &emptypb.Empty{}, toStatus(req.ThreadId, err)
+}
+
+func (s *Server) Breadth(ctx context.Context, req *pb.ThreadRequest) (*pb.Count, error) {
+	n, err := s.Engine.Breadth(req.ThreadId, ctx)
+	return &pb.Count{Count: int64(n)}, toStatus(req.ThreadId, err)
+}
+
+func (s *Server) Degree(ctx context.Context, req *pb.MessageRequest) (*pb.Count, error) {
+	n, err := s.Engine.Degree(req.ThreadId, message(req.MessageId), ctx)
+	return &pb.Count{Count: int64(n)}, toStatus(req.ThreadId, err)
+}
+
+func (s *Server) Delete(ctx context.Context, req *pb.MessageRequest) (*emptypb.Empty, error) {
+	err := s.Engine.Delete(req.ThreadId, message(req.MessageId), ctx)
+	return &emptypb.Empty{}, toStatus(req.ThreadId, err)
+}
+
+func (s *Server) Depth(ctx context.Context, req *pb.ThreadRequest) (*pb.Count, error) {
+	n, err := s.Engine.Depth(req.ThreadId, ctx)
+	return &pb.Count{Count: int64(n)}, toStatus(req.ThreadId, err)
+}
+
+func (s *Server) Get(ctx context.Context, req *pb.ThreadRequest) (*pb.ThreadTree, error) {
+	tree, err := s.Engine.Get(req.ThreadId, ctx)
+	if err != nil {
+		return nil, toStatus(req.ThreadId, err)
+	}
+	out, err := treeToProto(tree)
+	return out, toStatus(req.ThreadId, err)
+}
+
+func (s *Server) GetChildren(ctx context.Context, req *pb.GetChildrenRequest) (*pb.ThreadTree, error) {
+	tree, err := s.Engine.GetChildren(req.ThreadId, message(req.MessageId), int(req.Depth), ctx)
+	if err != nil {
+		return nil, toStatus(req.ThreadId, err)
+	}
+	out, err := treeToProto(tree)
+	return out, toStatus(req.ThreadId, err)
+}
+
+func (s *Server) GetLatestMessage(ctx context.Context, req *pb.ThreadRequest) (*pb.Message, error) {
+	m, err := s.Engine.GetLatestMessage(req.ThreadId, ctx)
+	if err != nil {
+		return nil, toStatus(req.ThreadId, err)
+	}
+	out, err := messageToProto(m)
+	return out, toStatus(req.ThreadId, err)
+}
+
+func (s *Server) Pick(ctx context.Context, req *pb.PickRequest) (*pb.Thread, error) {
+	thread, err := s.Engine.Pick(req.ThreadId, message(req.FromId), message(req.ToId), ctx)
+	if err != nil {
+		return nil, toStatus(req.ThreadId, err)
+	}
+	messages, err := messagesToProto(thread.Messages)
+	return &pb.Thread{Messages: messages}, toStatus(req.ThreadId, err)
+}
+
+func (s *Server) SetLatestMessage(ctx context.Context, req *pb.MessageRequest) (*pb.Message, error) {
+	m, err := s.Engine.SetLatestMessage(req.ThreadId, message(req.MessageId), ctx)
+	if err != nil {
+		return nil, toStatus(req.ThreadId, err)
+	}
+	out, err := messageToProto(m)
+	return out, toStatus(req.ThreadId, err)
+}
+
+func (s *Server) Size(ctx context.Context, req *pb.ThreadRequest) (*pb.Count, error) {
+	n, err := s.Engine.Size(req.ThreadId, ctx)
+	return &pb.Count{Count: int64(n)}, toStatus(req.ThreadId, err)
+}
+
+func (s *Server) StreamTree(req *pb.StreamTreeRequest, stream pb.TreeEngine_StreamTreeServer) error {
+	tree, err := s.Engine.Get(req.ThreadId, stream.Context())
+	if err != nil {
+		return toStatus(req.ThreadId, err)
+	}
+	size := int(req.ChunkSize)
+	if size <= 0 {
+		size = defaultChunkSize
+	}
+	incoming := map[string][]Impl.Triple{}
+	for _, r := range tree.Relations {
+		incoming[r.EndId] = append(incoming[r.EndId], r)
+	}
+	for start := 0; start < len(tree.Messages); start += size {
+		chunk := &pb.TreeChunk{}
+		for _, m := range tree.Messages[start:min(start+size, len(tree.Messages))] {
+			pm, err := messageToProto(m)
+			if err != nil {
+				return toStatus(req.ThreadId, err)
+			}
+			chunk.Messages = append(chunk.Messages, pm)
+			for _, r := range incoming[m.MessageId] {
+				chunk.Relations = append(chunk.Relations, tripleToProto(r))
+			}
+		}
+		if err := stream.Send(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) ListThreads(ctx context.Context, req *pb.ListThreadsRequest) (*pb.ThreadPage, error) {
+	opts := Impl.ListOptions{Limit: int(req.Limit), Cursor: req.Cursor, OrderBy: req.OrderBy}
+	if len(req.Metadata.GetFields()) > 0 {
+		opts.Metadata = req.Metadata.AsMap()
+	}
+	page, err := Impl.ListThreads(s.Engine, opts, ctx)
+	if err != nil {
+		return nil, toStatus("", err)
+	}
+	out, err := pageToProto(page)
+	return out, toStatus("", err)
+}
+
+func (s *Server) Info(ctx context.Context, _ *emptypb.Empty) (*pb.BackendInfo, error) {
+	info, err := Impl.Info(s.Engine, ctx)
+	if err != nil {
+		return nil, toStatus("", err)
+	}
+	return &pb.BackendInfo{Name: info.Name, Version: info.Version, Capabilities: info.Capabilities}, nil
+}
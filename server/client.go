@@ -0,0 +1,285 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	Impl "github.com/yashbonde/vriksham/impl"
+)
+
+// Client implements TreeEngine on top of the REST API of a Server, errors from the server come back as the errors of
+// the impl package so `errors.Is` works the same as with a local backend.
+//
+// Calls that can be repeated safely (every GET and setting the latest message) are retried on network errors, 429 and
+// 5xx responses, writes are sent once.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client  // http.DefaultClient when nil
+	Retries    int           // attempts after the first one for calls that can be repeated
+	Backoff    time.Duration // wait before the first retry, doubled for every retry after it
+}
+
+// NewClient returns a client for the server at `baseURL` with three retries starting at 100ms
+func NewClient(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/"), Retries: 3, Backoff: 100 * time.Millisecond}
+}
+
+// the client opens http:// and https:// DSNs with Impl.Open
+func init() {
+	for _, scheme := range []string{"http", "https"} {
+		Impl.Register(scheme, func(ctx context.Context, u *url.URL) (Impl.TreeEngine, error) {
+			client := NewClient(u.String())
+			return client, client.Connect(ctx)
+		})
+	}
+}
+
+// call is a single request to the server, `out` is filled from the response when it is not nil
+type call struct {
+	method     string
+	path       string
+	query      url.Values
+	body       any
+	out        any
+	idempotent bool
+}
+
+func threadPath(threadId string, parts ...string) string {
+	path := "/threads/" + url.PathEscape(threadId)
+	for _, p := range parts {
+		path += "/" + url.PathEscape(p)
+	}
+	return path
+}
+
+func (c Client) do(ctx context.Context, cl call) error {
+	var data []byte
+	if cl.body != nil {
+		var err error
+		if data, err = json.Marshal(cl.body); err != nil {
+			return err
+		}
+	}
+	target := c.BaseURL + cl.path
+	if len(cl.query) > 0 {
+		target += "?" + cl.query.Encode()
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	for attempt := 0; ; attempt++ {
+		retry, err := c.attempt(ctx, httpClient, cl, target, data)
+		if err == nil || !retry || !cl.idempotent || attempt >= c.Retries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.Backoff << attempt):
+		}
+	}
+}
+
+// attempt sends the request once and tells if a failure is worth retrying
+func (c Client) attempt(ctx context.Context, httpClient *http.Client, cl call, target string, data []byte) (bool, error) {
+	var body io.Reader = http.NoBody
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, cl.method, target, body)
+	if err != nil {
+		return false, err
+	}
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if cl.out == nil || resp.StatusCode == http.StatusNoContent {
+			return false, nil
+		}
+		if err := json.NewDecoder(resp.Body).Decode(cl.out); err != nil {
+			return false, fmt.Errorf("%s %s: decoding the response: %w", cl.method, cl.path, err)
+		}
+		return false, nil
+	}
+
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	errBody := ErrorResponse{}
+	data, _ = io.ReadAll(resp.Body)
+	if json.Unmarshal(data, &errBody) != nil || errBody.Code == "" {
+		return retry, fmt.Errorf("%s %s: %s", cl.method, cl.path, resp.Status)
+	}
+	return retry, Impl.ErrorFromCode(errBody.Code, errBody.Error, errBody.ThreadId, errBody.MessageId)
+}
+
+// messageId is the optional id a request is sent with
+func messageId(m *Impl.Message) string {
+	if m == nil {
+		return ""
+	}
+	return m.MessageId
+}
+
+// count calls one of the endpoints that return a single number under `name`
+func (c Client) count(ctx context.Context, path, name string, query url.Values) (int, error) {
+	out := map[string]int{}
+	err := c.do(ctx, call{method: "GET", path: path, query: query, out: &out, idempotent: true})
+	return out[name], err
+}
+
+// Connect checks the server is ready, the client itself holds no connection
+func (c Client) Connect(ctx context.Context) error {
+	return c.Ping(ctx)
+}
+
+// Close drops the idle connections of the http client
+func (c Client) Close() error {
+	if c.HTTPClient != nil {
+		c.HTTPClient.CloseIdleConnections()
+	}
+	return nil
+}
+
+// Ping asks the server whether its engine is ready
+func (c Client) Ping(ctx context.Context) error {
+	return c.do(ctx, call{method: "GET", path: "/readyz", idempotent: true})
+}
+
+// Info describes the client, with what the server says about its engine in Remote
+func (c Client) Info(ctx context.Context) (Impl.BackendInfo, error) {
+	remote := Impl.BackendInfo{}
+	err := c.do(ctx, call{method: "GET", path: "/info", out: &remote, idempotent: true})
+	return Impl.RemoteInfo("http", remote), err
+}
+
+// implement interface
+
+func (c Client) AddMessage(threadId string, a, b *Impl.Message, ctx context.Context) error {
+	body := AddMessageRequest{ParentId: messageId(b)}
+	if a != nil {
+		body.Message = *a
+	}
+	return c.do(ctx, call{method: "POST", path: threadPath(threadId, "messages"), body: body})
+}
+
+func (c Client) AddTree(threadId string, tree Impl.ThreadTree, ctx context.Context) error {
+	return c.do(ctx, call{method: "POST", path: threadPath(threadId, "tree"), body: tree})
+}
+
+func (c Client) Breadth(threadId string, ctx context.Context) (int, error) {
+	return c.count(ctx, threadPath(threadId, "breadth"), "breadth", nil)
+}
+
+func (c Client) Degree(threadId string, message *Impl.Message, ctx context.Context) (int, error) {
+	query := url.Values{}
+	if message != nil {
+		query.Set("message", message.MessageId)
+	}
+	return c.count(ctx, threadPath(threadId, "degree"), "degree", query)
+}
+
+func (c Client) Delete(threadId string, message *Impl.Message, ctx context.Context) error {
+	path := threadPath(threadId)
+	if message != nil {
+		path = threadPath(threadId, "messages", message.MessageId)
+	}
+	// a retried delete would report a message that is gone as missing, so it is sent once
+	return c.do(ctx, call{method: "DELETE", path: path})
+}
+
+func (c Client) Depth(threadId string, ctx context.Context) (int, error) {
+	return c.count(ctx, threadPath(threadId, "depth"), "depth", nil)
+}
+
+func (c Client) Get(threadId string, ctx context.Context) (Impl.ThreadTree, error) {
+	tree := Impl.ThreadTree{}
+	err := c.do(ctx, call{method: "GET", path: threadPath(threadId), out: &tree, idempotent: true})
+	return tree, err
+}
+
+func (c Client) GetChildren(threadId string, message *Impl.Message, depth int, ctx context.Context) (Impl.ThreadTree, error) {
+	query := url.Values{"depth": {strconv.Itoa(depth)}}
+	if message != nil {
+		query.Set("from", message.MessageId)
+	}
+	tree := Impl.ThreadTree{}
+	err := c.do(ctx, call{method: "GET", path: threadPath(threadId, "children"), query: query, out: &tree, idempotent: true})
+	return tree, err
+}
+
+func (c Client) ListThreads(opts Impl.ListOptions, ctx context.Context) (Impl.ThreadPage, error) {
+	query := url.Values{}
+	if opts.Limit != 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Cursor != "" {
+		query.Set("cursor", opts.Cursor)
+	}
+	if opts.OrderBy != "" {
+		query.Set("order", opts.OrderBy)
+	}
+	if len(opts.Metadata) > 0 {
+		data, err := json.Marshal(opts.Metadata)
+		if err != nil {
+			return Impl.ThreadPage{}, err
+		}
+		query.Set("metadata", string(data))
+	}
+	page := Impl.ThreadPage{}
+	err := c.do(ctx, call{method: "GET", path: "/threads", query: query, out: &page, idempotent: true})
+	return page, err
+}
+
+func (c Client) GetLatestMessage(threadId string, ctx context.Context) (Impl.Message, error) {
+	m := Impl.Message{}
+	err := c.do(ctx, call{method: "GET", path: threadPath(threadId, "latest"), out: &m, idempotent: true})
+	return m, err
+}
+
+func (c Client) Pick(threadId string, a, b *Impl.Message, ctx context.Context) (Impl.Thread, error) {
+	query := url.Values{}
+	if a != nil {
+		query.Set("from", a.MessageId)
+	}
+	if b != nil {
+		query.Set("to", b.MessageId)
+	}
+	thread := Impl.Thread{}
+	err := c.do(ctx, call{method: "GET", path: threadPath(threadId, "pick"), query: query, out: &thread, idempotent: true})
+	return thread, err
+}
+
+func (c Client) SetLatestMessage(threadId string, latestMessage *Impl.Message, ctx context.Context) (Impl.Message, error) {
+	m := Impl.Message{}
+	err := c.do(ctx, call{
+		method:     "PUT",
+		path:       threadPath(threadId, "latest"),
+		body:       SetLatestRequest{MessageId: messageId(latestMessage)},
+		out:        &m,
+		idempotent: true,
+	})
+	return m, err
+}
+
+func (c Client) Size(threadId string, ctx context.Context) (int, error) {
+	return c.count(ctx, threadPath(threadId, "size"), "size", nil)
+}
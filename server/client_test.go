@@ -0,0 +1,122 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	Impl "github.com/yashbonde/vriksham/impl"
+	"github.com/yashbonde/vriksham/impl/enginetest"
+	"github.com/yashbonde/vriksham/server"
+)
+
+func TestClient(t *testing.T) {
+	enginetest.Run(t, func(t *testing.T) Impl.TreeEngine {
+		ts := httptest.NewServer(server.New(&Impl.Backend_Memory{}))
+		t.Cleanup(ts.Close)
+		return server.NewClient(ts.URL)
+	})
+}
+
+// flaky fails the first `failures` requests with a 503 before handing them to the server
+func flaky(failures int32, next http.Handler) (http.Handler, *atomic.Int32) {
+	requests := &atomic.Int32{}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}), requests
+}
+
+func TestClientRetries(t *testing.T) {
+	ctx := context.Background()
+	engine := &Impl.Backend_Memory{}
+	demo := Impl.GetDemoTree()
+	engine.AddTree(demo.Root.ThreadId, *demo, ctx)
+
+	handler, requests := flaky(2, server.New(engine))
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+	client := server.NewClient(ts.URL)
+	client.Backoff = time.Millisecond
+
+	n, err := client.Size(demo.Root.ThreadId, ctx)
+	if err != nil || n != 28 {
+		t.Errorf("Size: got %d, %v after retrying", n, err)
+	}
+	if requests.Load() != 3 {
+		t.Errorf("Size: got %d requests, want 3", requests.Load())
+	}
+
+	// writes are not retried
+	requests.Store(0)
+	err = client.AddMessage(demo.Root.ThreadId, &Impl.Message{MessageId: "new_00"}, nil, ctx)
+	if err == nil || requests.Load() != 1 {
+		t.Errorf("AddMessage: got %v after %d requests, want a failure after 1", err, requests.Load())
+	}
+
+	// retries give up after Retries
+	requests.Store(-10)
+	client.Retries = 2
+	if _, err := client.Size(demo.Root.ThreadId, ctx); err == nil || requests.Load() != -7 {
+		t.Errorf("Size: got %v after %d requests, want a failure after 3", err, requests.Load()+10)
+	}
+}
+
+func TestClientContext(t *testing.T) {
+	blocked := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		close(blocked)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := server.NewClient(ts.URL).Get("tree_0000", ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get: got %v, want context.DeadlineExceeded", err)
+	}
+	<-blocked
+}
+
+func TestClientLifecycle(t *testing.T) {
+	ctx := context.Background()
+	engine := &Impl.Backend_SQLite{Path: ":memory:"}
+	if err := engine.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	ts := httptest.NewServer(server.New(engine))
+	defer ts.Close()
+	client := server.NewClient(ts.URL)
+	client.Backoff = time.Millisecond
+
+	if err := client.Connect(ctx); err != nil {
+		t.Errorf("Connect: %v", err)
+	}
+	if opened, err := Impl.Open(ctx, ts.URL); err != nil {
+		t.Errorf("Open(%s): %v", ts.URL, err)
+	} else if _, ok := opened.(*server.Client); !ok {
+		t.Errorf("Open(%s): got a %T", ts.URL, opened)
+	}
+	info, err := client.Info(ctx)
+	if err != nil || info.Name != "http" || !info.Has(Impl.CapabilityRemote) || info.Remote == nil || info.Remote.Name != "sqlite" {
+		t.Errorf("Info: got %+v, %v", info, err)
+	}
+
+	// the server stays up but stops being ready
+	engine.Close()
+	if err := client.Ping(ctx); !errors.Is(err, Impl.ErrNotConnected) {
+		t.Errorf("Ping: got %v, want ErrNotConnected", err)
+	}
+	resp, err := http.Get(ts.URL + "/healthz")
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /healthz: got %v, %v", resp, err)
+	}
+}
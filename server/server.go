@@ -0,0 +1,321 @@
+/*
+Package server exposes a TreeEngine over HTTP with JSON bodies, every method of the interface has an endpoint:
+
+	POST   /threads/{thread}/messages              AddMessage, body {"message": {...}, "parent_id": "msg_27"}
+	POST   /threads/{thread}/tree                  AddTree, body is a ThreadTree
+	GET    /threads/{thread}                       Get
+	GET    /threads/{thread}/children?from=&depth= GetChildren, `from` defaults to the root and `depth` to 1
+	GET    /threads/{thread}/latest                GetLatestMessage
+	PUT    /threads/{thread}/latest                SetLatestMessage, body {"message_id": "msg_07"}
+	GET    /threads/{thread}/pick?from=&to=        Pick, `from` defaults to the root and `to` to the latest message
+	DELETE /threads/{thread}                       Delete the whole thread
+	DELETE /threads/{thread}/messages/{message}    Delete a message and everything below it
+	GET    /threads/{thread}/size                  Size, {"size": 28}
+	GET    /threads/{thread}/breadth               Breadth, {"breadth": 9}
+	GET    /threads/{thread}/depth                 Depth, {"depth": 8}
+	GET    /threads/{thread}/degree?message=       Degree, {"degree": 6}
+
+and there are endpoints for the optional interfaces of the impl package and for probes:
+
+	GET    /threads?limit=&cursor=&order=&metadata= ListThreads, `order` is thread_id or activity, `metadata` is a JSON
+	                                               object and the response is an Impl.ThreadPage
+	GET    /healthz                                the process is up, always {"status": "ok"}
+	GET    /readyz                                 the engine can be reached, 503 when its Ping fails
+	GET    /info                                   the Impl.BackendInfo of the engine
+
+Errors come back as {"error": "...", "code": "thread_not_found", "thread_id": "...", "message_id": "..."} with a status
+that matches the error, see errorStatus.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	Impl "github.com/yashbonde/vriksham/impl"
+)
+
+// Server serves the REST API on top of any TreeEngine
+type Server struct {
+	Engine Impl.TreeEngine
+	mux    *http.ServeMux
+}
+
+// New returns a Server for `engine` with all the routes registered
+func New(engine Impl.TreeEngine) *Server {
+	s := &Server{Engine: engine, mux: http.NewServeMux()}
+	s.mux.HandleFunc("POST /threads/{thread}/messages", s.addMessage)
+	s.mux.HandleFunc("POST /threads/{thread}/tree", s.addTree)
+	s.mux.HandleFunc("GET /threads/{thread}", s.get)
+	s.mux.HandleFunc("GET /threads/{thread}/children", s.getChildren)
+	s.mux.HandleFunc("GET /threads/{thread}/latest", s.getLatestMessage)
+	s.mux.HandleFunc("PUT /threads/{thread}/latest", s.setLatestMessage)
+	s.mux.HandleFunc("GET /threads/{thread}/pick", s.pick)
+	s.mux.HandleFunc("DELETE /threads/{thread}", s.delete)
+	s.mux.HandleFunc("DELETE /threads/{thread}/messages/{message}", s.delete)
+	s.mux.HandleFunc("GET /threads/{thread}/size", s.count("size", s.Engine.Size))
+	s.mux.HandleFunc("GET /threads/{thread}/breadth", s.count("breadth", s.Engine.Breadth))
+	s.mux.HandleFunc("GET /threads/{thread}/depth", s.count("depth", s.Engine.Depth))
+	s.mux.HandleFunc("GET /threads/{thread}/degree", s.degree)
+	s.mux.HandleFunc("GET /threads", s.listThreads)
+	s.mux.HandleFunc("GET /healthz", s.healthz)
+	s.mux.HandleFunc("GET /readyz", s.readyz)
+	s.mux.HandleFunc("GET /info", s.info)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// request and response bodies
+
+// AddMessageRequest is the body of POST /threads/{thread}/messages, an empty ParentId adds to the root
+type AddMessageRequest struct {
+	Message  Impl.Message `json:"message"`
+	ParentId string       `json:"parent_id,omitempty"`
+}
+
+// SetLatestRequest is the body of PUT /threads/{thread}/latest
+type SetLatestRequest struct {
+	MessageId string `json:"message_id"`
+}
+
+// ErrorResponse is the body of every response that is not a 2xx
+type ErrorResponse struct {
+	Error     string `json:"error"`
+	Code      string `json:"code"`
+	ThreadId  string `json:"thread_id,omitempty"`
+	MessageId string `json:"message_id,omitempty"`
+}
+
+// errorStatus is the status for each Impl.ErrorCode, anything else is a 500
+var errorStatus = map[string]int{
+	"thread_not_found":     http.StatusNotFound,
+	"message_not_found":    http.StatusNotFound,
+	"no_latest":            http.StatusNotFound,
+	"duplicate_message":    http.StatusConflict,
+	"invalid_message":      http.StatusBadRequest,
+	"invalid_tree":         http.StatusBadRequest,
+	"depth_exceeded":       http.StatusBadRequest,
+	"not_connected":        http.StatusServiceUnavailable,
+	"invalid_list_options": http.StatusBadRequest,
+	"unsupported":          http.StatusNotImplemented,
+}
+
+// badRequest is for requests that never made it to the engine
+type badRequest struct{ error }
+
+func (s *Server) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	body := ErrorResponse{Error: err.Error(), Code: "internal", ThreadId: r.PathValue("thread")}
+	status := http.StatusInternalServerError
+	var bad badRequest
+	if errors.As(err, &bad) {
+		body.Code, status = "bad_request", http.StatusBadRequest
+	}
+	if code := Impl.ErrorCode(err); code != "" {
+		body.Code, status = code, errorStatus[code]
+	}
+	var messageErr *Impl.MessageError
+	if errors.As(err, &messageErr) {
+		body.ThreadId, body.MessageId = messageErr.ThreadId, messageErr.MessageId
+	}
+	writeJSON(w, status, body)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func readJSON(r *http.Request, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return badRequest{fmt.Errorf("bad request body: %w", err)}
+	}
+	return nil
+}
+
+// message turns an optional id from the request into a message, nil when it is empty
+func message(id string) *Impl.Message {
+	if id == "" {
+		return nil
+	}
+	return &Impl.Message{MessageId: id}
+}
+
+// handlers
+
+func (s *Server) addMessage(w http.ResponseWriter, r *http.Request) {
+	body := AddMessageRequest{}
+	if err := readJSON(r, &body); err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	threadId := r.PathValue("thread")
+	if err := s.Engine.AddMessage(threadId, &body.Message, message(body.ParentId), r.Context()); err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	// the latest flag is never set by AddMessage
+	body.Message.Latest = false
+	writeJSON(w, http.StatusCreated, body.Message)
+}
+
+func (s *Server) addTree(w http.ResponseWriter, r *http.Request) {
+	tree := Impl.ThreadTree{}
+	if err := readJSON(r, &tree); err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	if err := s.Engine.AddTree(r.PathValue("thread"), tree, r.Context()); err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request) {
+	tree, err := s.Engine.Get(r.PathValue("thread"), r.Context())
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tree)
+}
+
+func (s *Server) getChildren(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	depth := 1
+	if d := query.Get("depth"); d != "" {
+		var err error
+		if depth, err = strconv.Atoi(d); err != nil {
+			s.writeError(w, r, badRequest{fmt.Errorf("bad depth %q", d)})
+			return
+		}
+	}
+	tree, err := s.Engine.GetChildren(r.PathValue("thread"), message(query.Get("from")), depth, r.Context())
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tree)
+}
+
+func (s *Server) listThreads(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	opts := Impl.ListOptions{Cursor: query.Get("cursor"), OrderBy: query.Get("order")}
+	if l := query.Get("limit"); l != "" {
+		var err error
+		if opts.Limit, err = strconv.Atoi(l); err != nil {
+			s.writeError(w, r, badRequest{fmt.Errorf("bad limit %q", l)})
+			return
+		}
+	}
+	if m := query.Get("metadata"); m != "" {
+		if err := json.Unmarshal([]byte(m), &opts.Metadata); err != nil {
+			s.writeError(w, r, badRequest{fmt.Errorf("bad metadata: %w", err)})
+			return
+		}
+	}
+	page, err := Impl.ListThreads(s.Engine, opts, r.Context())
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) getLatestMessage(w http.ResponseWriter, r *http.Request) {
+	m, err := s.Engine.GetLatestMessage(r.PathValue("thread"), r.Context())
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, m)
+}
+
+func (s *Server) setLatestMessage(w http.ResponseWriter, r *http.Request) {
+	body := SetLatestRequest{}
+	if err := readJSON(r, &body); err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	m, err := s.Engine.SetLatestMessage(r.PathValue("thread"), message(body.MessageId), r.Context())
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, m)
+}
+
+func (s *Server) pick(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	thread, err := s.Engine.Pick(r.PathValue("thread"), message(query.Get("from")), message(query.Get("to")), r.Context())
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	if thread.Messages == nil {
+		thread.Messages = []Impl.Message{}
+	}
+	writeJSON(w, http.StatusOK, thread)
+}
+
+func (s *Server) delete(w http.ResponseWriter, r *http.Request) {
+	if err := s.Engine.Delete(r.PathValue("thread"), message(r.PathValue("message")), r.Context()); err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// count serves the methods that return a single number under `name`
+func (s *Server) count(name string, method func(threadId string, ctx context.Context) (int, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		n, err := method(r.PathValue("thread"), r.Context())
+		if err != nil {
+			s.writeError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]int{name: n})
+	}
+}
+
+func (s *Server) degree(w http.ResponseWriter, r *http.Request) {
+	n, err := s.Engine.Degree(r.PathValue("thread"), message(r.URL.Query().Get("message")), r.Context())
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"degree": n})
+}
+
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// readyz reports any failed Ping as ErrNotConnected so it comes back as a 503
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	if err := Impl.Ping(s.Engine, r.Context()); err != nil {
+		if !errors.Is(err, Impl.ErrNotConnected) {
+			err = fmt.Errorf("%w: %v", Impl.ErrNotConnected, err)
+		}
+		s.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) info(w http.ResponseWriter, r *http.Request) {
+	info, err := Impl.Info(s.Engine, r.Context())
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
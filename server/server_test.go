@@ -0,0 +1,143 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	Impl "github.com/yashbonde/vriksham/impl"
+	"github.com/yashbonde/vriksham/server"
+)
+
+// call sends a request with an optional JSON body and decodes the JSON response into `out` when it is not nil
+func call(t *testing.T, ts *httptest.Server, method, path string, body, out any) int {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, ts.URL+path, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: decoding the response: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func expectStatus(t *testing.T, name string, got, want int) {
+	t.Helper()
+	if got != want {
+		t.Errorf("%s: got status %d, want %d", name, got, want)
+	}
+}
+
+func TestServer(t *testing.T) {
+	ts := httptest.NewServer(server.New(&Impl.Backend_Memory{}))
+	defer ts.Close()
+
+	demo := Impl.GetDemoTree()
+	expectStatus(t, "POST tree", call(t, ts, "POST", "/threads/tree_0000/tree", demo, nil), http.StatusNoContent)
+
+	tree := Impl.ThreadTree{}
+	expectStatus(t, "GET thread", call(t, ts, "GET", "/threads/tree_0000", nil, &tree), http.StatusOK)
+	if len(tree.Messages) != 28 || len(tree.Relations) != 28 || tree.Root.ThreadId != "tree_0000" {
+		t.Errorf("GET thread: got %d messages and %d relations", len(tree.Messages), len(tree.Relations))
+	}
+
+	tree = Impl.ThreadTree{}
+	expectStatus(t, "GET children", call(t, ts, "GET", "/threads/tree_0000/children?from=msg_06&depth=3", nil, &tree), http.StatusOK)
+	if len(tree.Messages) != 5 {
+		t.Errorf("GET children: got %d messages, want 5", len(tree.Messages))
+	}
+
+	added := server.AddMessageRequest{Message: Impl.Message{MessageId: "new_00", Role: "user", Content: "hello"}, ParentId: "msg_27"}
+	m := Impl.Message{}
+	expectStatus(t, "POST message", call(t, ts, "POST", "/threads/tree_0000/messages", added, &m), http.StatusCreated)
+	if m.MessageId != "new_00" || m.Content != "hello" {
+		t.Errorf("POST message: got %+v", m)
+	}
+
+	m = Impl.Message{}
+	expectStatus(t, "PUT latest", call(t, ts, "PUT", "/threads/tree_0000/latest", server.SetLatestRequest{MessageId: "new_00"}, &m), http.StatusOK)
+	if m.MessageId != "new_00" || !m.Latest {
+		t.Errorf("PUT latest: got %+v", m)
+	}
+	m = Impl.Message{}
+	expectStatus(t, "GET latest", call(t, ts, "GET", "/threads/tree_0000/latest", nil, &m), http.StatusOK)
+	if m.MessageId != "new_00" {
+		t.Errorf("GET latest: got %+v", m)
+	}
+
+	thread := Impl.Thread{}
+	expectStatus(t, "GET pick", call(t, ts, "GET", "/threads/tree_0000/pick?from=msg_26", nil, &thread), http.StatusOK)
+	if len(thread.Messages) != 3 || thread.Messages[2].MessageId != "new_00" {
+		t.Errorf("GET pick: got %+v", thread.Messages)
+	}
+
+	for _, tc := range []struct {
+		path, key string
+		want      int
+	}{
+		{"size", "size", 29},
+		{"breadth", "breadth", 9},
+		{"depth", "depth", 9},
+		{"degree", "degree", 6},
+		{"degree?message=msg_06", "degree", 2},
+	} {
+		got := map[string]int{}
+		expectStatus(t, "GET "+tc.path, call(t, ts, "GET", "/threads/tree_0000/"+tc.path, nil, &got), http.StatusOK)
+		if got[tc.key] != tc.want {
+			t.Errorf("GET %s: got %v, want %d", tc.path, got, tc.want)
+		}
+	}
+
+	expectStatus(t, "DELETE message", call(t, ts, "DELETE", "/threads/tree_0000/messages/msg_06", nil, nil), http.StatusNoContent)
+	expectStatus(t, "DELETE thread", call(t, ts, "DELETE", "/threads/tree_0000", nil, nil), http.StatusNoContent)
+	expectStatus(t, "GET deleted thread", call(t, ts, "GET", "/threads/tree_0000", nil, nil), http.StatusNotFound)
+}
+
+func TestServerErrors(t *testing.T) {
+	ts := httptest.NewServer(server.New(&Impl.Backend_Memory{}))
+	defer ts.Close()
+	call(t, ts, "POST", "/threads/tree_0000/tree", Impl.GetDemoTree(), nil)
+
+	for _, tc := range []struct {
+		method, path string
+		body         any
+		status       int
+		code         string
+		messageId    string
+	}{
+		{"GET", "/threads/unknown", nil, http.StatusNotFound, "thread_not_found", ""},
+		{"GET", "/threads/tree_0000/pick?to=unknown", nil, http.StatusNotFound, "message_not_found", "unknown"},
+		{"GET", "/threads/tree_0000/children?depth=11", nil, http.StatusBadRequest, "depth_exceeded", ""},
+		{"GET", "/threads/tree_0000/children?depth=two", nil, http.StatusBadRequest, "bad_request", ""},
+		{"POST", "/threads/tree_0000/messages", server.AddMessageRequest{Message: Impl.Message{MessageId: "msg_00"}}, http.StatusConflict, "duplicate_message", "msg_00"},
+		{"POST", "/threads/tree_0000/messages", server.AddMessageRequest{}, http.StatusBadRequest, "invalid_message", ""},
+		{"POST", "/threads/other/tree", Impl.GetDemoTree(), http.StatusBadRequest, "invalid_tree", ""},
+		{"PUT", "/threads/tree_0000/latest", "not an object", http.StatusBadRequest, "bad_request", ""},
+	} {
+		name := tc.method + " " + tc.path
+		body := server.ErrorResponse{}
+		expectStatus(t, name, call(t, ts, tc.method, tc.path, tc.body, &body), tc.status)
+		if body.Code != tc.code || body.MessageId != tc.messageId || body.Error == "" {
+			t.Errorf("%s: got %+v, want code %q for message %q", name, body, tc.code, tc.messageId)
+		}
+	}
+}